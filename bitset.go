@@ -2,19 +2,45 @@ package bitset
 
 import (
 	"bytes"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 	"math/bits"
+	"math/rand"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
+	"unsafe"
 )
 
 type BitSet struct {
-	size  int // the number of bits the bitset holds
-	words []uint64
+	size   int // the number of bits the bitset holds
+	words  []uint64
+	growth GrowthPolicy // nil means AmortizedGrowth
 }
 
-// NewBitSetWithInitialSize initializes and returns a BitSet holding the given number of bits.
+// GrowthPolicy decides how many words a bitset holding currentWords words grows to when it needs
+// neededWords words. Results smaller than neededWords are raised to it.
+type GrowthPolicy func(currentWords, neededWords int) int
+
+// AmortizedGrowth is the default GrowthPolicy. It at least doubles the number of words, so setting
+// bits in ascending order reallocates a logarithmic number of times.
+func AmortizedGrowth(currentWords, neededWords int) int {
+	return max(neededWords, 2*currentWords)
+}
+
+// ExactGrowth is a GrowthPolicy that grows to exactly the number of words needed, for callers that
+// care more about memory than reallocation.
+func ExactGrowth(currentWords, neededWords int) int {
+	return neededWords
+}
+
+// NewBitSetWithInitialSize initializes and returns a BitSet holding the given number of bits. A
+// negative numBits is treated as 0, returning an empty bitset.
 func NewBitSetWithInitialSize(numBits int) *BitSet {
-	numWords := 1 + int(float64(numBits)/64.0)
+	numBits = max(numBits, 0)
+	numWords := max(1, (numBits+63)/64)
 	return &BitSet{
 		size:  numBits,
 		words: make([]uint64, numWords),
@@ -26,15 +52,276 @@ func NewBitSet() *BitSet {
 	return NewBitSetWithInitialSize(64)
 }
 
+// NewBitSetFromWords returns a BitSet of the given size backed directly by words, with bit i held
+// in word i/64 at position i%64. The slice isn't copied, so the caller must not modify it
+// afterward. Panics if size < 0, size > len(words)*64 or words has any bit at or above size set.
+func NewBitSetFromWords(words []uint64, size int) *BitSet {
+	if size < 0 || size > len(words)*64 {
+		panic(fmt.Sprintf("bitset: size %d out of range for %d words", size, len(words)))
+	}
+	for i := size / 64; i < len(words); i++ {
+		if words[i]&^mask(^uint64(0), size-i*64) != 0 {
+			panic(fmt.Sprintf("bitset: word %d has bits set at or above size %d", i, size))
+		}
+	}
+	return &BitSet{size: size, words: words}
+}
+
+// NewBitSetFromHex initializes and returns a BitSet from a hex string as produced by ToHex. The
+// returned bitset holds 8 bits per decoded byte. Errors if s has odd length or isn't valid hex.
+func NewBitSetFromHex(s string) (*BitSet, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex string %q: %w", s, err)
+	}
+	bs := NewBitSetWithInitialSize(len(b) * 8)
+	for i, byt := range b {
+		bs.words[i/8] |= uint64(byt) << (8 * (i % 8))
+	}
+	return bs, nil
+}
+
+// ParseBinaryString initializes and returns a BitSet from a binary string of '0' and '1' characters
+// laid out like String and StringN, i.e. the last character is bit 0. The returned bitset's size is
+// len(s). Errors on any other character.
+func ParseBinaryString(s string) (*BitSet, error) {
+	bs := NewBitSetWithInitialSize(len(s))
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '1':
+			bs.set(len(s) - 1 - i)
+		case '0':
+		default:
+			return nil, fmt.Errorf("invalid character %q at position %d of binary string", s[i], i)
+		}
+	}
+	return bs, nil
+}
+
+// NewBitSetFromBoolSlice initializes and returns a BitSet of size len(b) where bit i is set if b[i]
+// is true.
+func NewBitSetFromBoolSlice(b []bool) *BitSet {
+	bs := NewBitSetWithInitialSize(len(b))
+	for i, v := range b {
+		if v {
+			bs.set(i)
+		}
+	}
+	return bs
+}
+
+// NewBitSetFromIndicesStrict initializes and returns a BitSet of the given size with the bits at
+// the given indices set. Errors without building a bitset if size is negative or any index is
+// outside [0, size).
+func NewBitSetFromIndicesStrict(indices []int, size int) (*BitSet, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("invalid bitset size %d", size)
+	}
+	for _, idx := range indices {
+		if idx < 0 || idx >= size {
+			return nil, fmt.Errorf("bit index %d out of range of bitset of size %d", idx, size)
+		}
+	}
+	bs := NewBitSetWithInitialSize(size)
+	for _, idx := range indices {
+		bs.set(idx)
+	}
+	return bs, nil
+}
+
+// Clone returns a copy of the bitset, including its growth policy.
+func (bs *BitSet) Clone() *BitSet {
+	return &BitSet{size: bs.size, words: slices.Clone(bs.words), growth: bs.growth}
+}
+
+// CopyInto makes dst an exact copy of the bitset, reusing dst's words when they have enough
+// capacity so no allocation is needed, unlike Clone.
+func (bs *BitSet) CopyInto(dst *BitSet) {
+	words := dst.words[:cap(dst.words)]
+	if len(words) < len(bs.words) {
+		words = make([]uint64, len(bs.words))
+	}
+	copy(words, bs.words)
+	clear(words[len(bs.words):])
+	dst.size, dst.words = bs.size, words[:len(bs.words)]
+}
+
 // Size returns the number of bits the bitset holds
 func (bs *BitSet) Size() int {
 	return bs.size
 }
 
-// Set sets the Nth bit to 1.
-func (bs *BitSet) Set(n int) {
+// WordCount returns the number of 64-bit words backing the bitset.
+func (bs *BitSet) WordCount() int {
+	return len(bs.words)
+}
+
+// Words returns a copy of the 64-bit words backing the bitset, with bit i held in word i/64 at
+// position i%64. Since it's a copy, modifying it doesn't change the bitset.
+func (bs *BitSet) Words() []uint64 {
+	return slices.Clone(bs.words)
+}
+
+// Word returns the Ith 64-bit word, holding bits [64*i, 64*i+64), or 0 if i is out of range.
+func (bs *BitSet) Word(i int) uint64 {
+	return bs.getWord(i)
+}
+
+// WordAt returns the Ith 64-bit word without copying the backing slice. Ok is false if i is outside
+// [0, Len()). Together with Len it allows iterating the words one at a time.
+func (bs *BitSet) WordAt(i int) (word uint64, ok bool) {
+	if i < 0 || i >= len(bs.words) {
+		return 0, false
+	}
+	return bs.words[i], true
+}
+
+// Len returns the number of 64-bit words backing the bitset. It's the same as WordCount.
+func (bs *BitSet) Len() int {
+	return len(bs.words)
+}
+
+// SetWord replaces the Ith 64-bit word, holding bits [64*i, 64*i+64), with w, growing the bitset
+// so that the highest set bit of w is in range. Negative i is ignored.
+func (bs *BitSet) SetWord(i int, w uint64) {
+	if i < 0 {
+		return
+	}
+	if w != 0 {
+		bs.resize(i*64 + 63 - bits.LeadingZeros64(w))
+	}
+	if i < len(bs.words) {
+		bs.words[i] = w
+	}
+}
+
+// OrWords ORs words into the receiver, with words[i] holding bits [64*i, 64*i+64) like the words
+// of a bitset. The bitset is grown to hold at least len(words)*64 bits.
+func (bs *BitSet) OrWords(words []uint64) *BitSet {
+	bs.Grow(len(words) * 64)
+	for i, w := range words {
+		bs.words[i] |= w
+	}
+	return bs
+}
+
+// ApplyWordMask replaces the word at wordIndex with itself AND (&) m, clearing the bits of that
+// word that aren't set in m. Does nothing if wordIndex is out of range.
+func (bs *BitSet) ApplyWordMask(wordIndex int, m uint64) {
+	if wordIndex >= 0 && wordIndex < len(bs.words) {
+		bs.words[wordIndex] &= m
+	}
+}
+
+// SizeInBytes returns an estimate of the memory used by the bitset: its words plus the fixed size
+// of the BitSet struct. It doesn't include padding added by Go's allocator.
+func (bs *BitSet) SizeInBytes() int {
+	return len(bs.words)*8 + int(unsafe.Sizeof(*bs))
+}
+
+// Cap returns the number of bits the currently allocated words can hold without reallocating.
+func (bs *BitSet) Cap() int {
+	return len(bs.words) * 64
+}
+
+// SetGrowthPolicy sets the policy used to decide how many words to allocate when the bitset grows.
+// A nil policy restores the default, AmortizedGrowth.
+func (bs *BitSet) SetGrowthPolicy(policy GrowthPolicy) *BitSet {
+	bs.growth = policy
+	return bs
+}
+
+// Grow ensures the bitset can hold numBits bits, raising its size to numBits if it is smaller.
+// No bit values are changed.
+func (bs *BitSet) Grow(numBits int) {
+	if numBits > bs.size {
+		bs.resize(numBits - 1)
+	}
+}
+
+// Resize sets the size of the bitset to exactly newSize bits. Growing adds clear bits. Shrinking
+// clears the bits at or above newSize, so growing again later doesn't bring them back, and drops
+// the words no longer needed. Negative newSize is treated as 0.
+func (bs *BitSet) Resize(newSize int) {
+	newSize = max(newSize, 0)
+	if newSize >= bs.size {
+		bs.Grow(newSize)
+		return
+	}
+	bs.ClearRange(newSize, bs.size)
+	bs.size = newSize
+	bs.words = bs.words[:max(1, (newSize+63)/64)]
+}
+
+// Compact releases trailing all-zero words, keeping at least one, and lowers the size of the
+// bitset to one past its highest set bit.
+func (bs *BitSet) Compact() {
+	numWords := len(bs.words)
+	for numWords > 1 && bs.words[numWords-1] == 0 {
+		numWords--
+	}
+	if numWords < cap(bs.words) {
+		words := make([]uint64, numWords)
+		copy(words, bs.words)
+		bs.words = words
+	}
+	bs.size = bs.LastSetBit() + 1
+}
+
+// Trim lowers the size of the bitset to one past its highest set bit, or 0 if no bits are set, and
+// drops the words beyond it. It's the same as Compact.
+func (bs *BitSet) Trim() {
+	bs.Compact()
+}
+
+// Set sets the Nth bit to 1, growing the bitset if n >= bitset.size. Negative n is ignored.
+// Returns the receiver so calls can be chained.
+func (bs *BitSet) Set(n int) *BitSet {
+	if n < 0 {
+		return bs
+	}
 	bs.resize(n)
 	bs.set(n)
+	return bs
+}
+
+// SetRange sets the bits in [lo, hi) to 1, growing the bitset if hi > bitset.size. Lo is clamped
+// to 0 and nothing is done if hi <= lo.
+func (bs *BitSet) SetRange(lo, hi int) *BitSet {
+	lo = max(lo, 0)
+	if hi <= lo {
+		return bs
+	}
+	bs.resize(hi - 1)
+	bs.rangeWords(lo, hi, func(wordIdx int, m uint64) { bs.words[wordIdx] |= m })
+	return bs
+}
+
+// ClearRange zeroes the bits in [lo, hi). Like Clear, it never grows the bitset.
+func (bs *BitSet) ClearRange(lo, hi int) *BitSet {
+	lo, hi = max(lo, 0), min(hi, bs.size)
+	if hi <= lo {
+		return bs
+	}
+	bs.rangeWords(lo, hi, func(wordIdx int, m uint64) { bs.words[wordIdx] &^= m })
+	return bs
+}
+
+// SetRangeValue sets the bits in [lo, hi) to 1 if v is true, like SetRange, and zeroes them
+// otherwise, like ClearRange.
+func (bs *BitSet) SetRangeValue(lo, hi int, v bool) *BitSet {
+	if v {
+		return bs.SetRange(lo, hi)
+	}
+	return bs.ClearRange(lo, hi)
+}
+
+// SetBool sets the Nth bit to 1 if v is true, growing the bitset if needed, and zeroes it otherwise.
+func (bs *BitSet) SetBool(n int, v bool) *BitSet {
+	if v {
+		return bs.Set(n)
+	}
+	return bs.Clear(n)
 }
 
 // SetBits sets the bits at the given indices.
@@ -44,28 +331,136 @@ func (bs *BitSet) SetBits(indices []int) {
 	}
 }
 
-// Clear zeroes the Nth bit. Errors if n < 0 or n >= bitset.size
-func (bs *BitSet) Clear(n int) {
-	bs.resize(n)
+// SetBitsChecked sets the bits at the given non-negative indices, growing the bitset as needed,
+// and returns the negative indices it skipped in the order they appeared.
+func (bs *BitSet) SetBitsChecked(indices []int) (invalid []int) {
+	for _, idx := range indices {
+		if idx < 0 {
+			invalid = append(invalid, idx)
+			continue
+		}
+		bs.Set(idx)
+	}
+	return invalid
+}
+
+// SetBitsSorted sets the bits at the given indices, which should be in ascending order. It grows the
+// bitset at most once and, for sorted indices, writes each affected word once, so it's faster than
+// SetBits. Unsorted indices are still set correctly, just with more word writes. Negative indices
+// are ignored.
+func (bs *BitSet) SetBitsSorted(indices []int) {
+	if len(indices) == 0 {
+		return
+	}
+	highest := slices.Max(indices)
+	if highest < 0 {
+		return
+	}
+	bs.resize(highest)
+	wordIdx, word := -1, uint64(0)
+	for _, idx := range indices {
+		if idx < 0 {
+			continue
+		}
+		if idx/64 != wordIdx {
+			if wordIdx >= 0 {
+				bs.words[wordIdx] |= word
+			}
+			wordIdx, word = idx/64, 0
+		}
+		word |= 1 << (idx % 64)
+	}
+	bs.words[wordIdx] |= word
+}
+
+// SetBitsAtomic sets the bits at the given indices only if every index is in range, i.e.
+// 0 <= idx < bitset.size. Returns an error and leaves the bitset unchanged otherwise.
+func (bs *BitSet) SetBitsAtomic(indices []int) error {
+	for _, idx := range indices {
+		if err := bs.checkValidBit(idx); err != nil {
+			return err
+		}
+	}
+	for _, idx := range indices {
+		bs.set(idx)
+	}
+	return nil
+}
+
+// Clear zeroes the Nth bit. Since bits outside [0, bitset.size) are never set, it does nothing
+// and doesn't grow the bitset if n < 0 or n >= bitset.size.
+func (bs *BitSet) Clear(n int) *BitSet {
+	if n < 0 || n >= bs.size {
+		return bs
+	}
 	bs.clear(n)
+	return bs
 }
 
 // ClearBits zeroes the bits at the given indices.
 func (bs *BitSet) ClearBits(indices []int) {
 	for _, idx := range indices {
-		bs.clear(idx)
+		bs.Clear(idx)
+	}
+}
+
+// ClearBitsSorted zeroes the bits at the given indices, which must be in ascending order. It
+// writes each affected word once, so it's faster than ClearBits. Indices outside
+// [0, bitset.size) are ignored.
+func (bs *BitSet) ClearBitsSorted(indices []int) {
+	wordIdx, word := -1, uint64(0)
+	for _, idx := range indices {
+		if idx < 0 || idx >= bs.size {
+			continue
+		}
+		if idx/64 != wordIdx {
+			if wordIdx >= 0 {
+				bs.words[wordIdx] &^= word
+			}
+			wordIdx, word = idx/64, 0
+		}
+		word |= 1 << (idx % 64)
+	}
+	if wordIdx >= 0 {
+		bs.words[wordIdx] &^= word
 	}
 }
 
-// ClearAll clears all bits.
+// ClearAll clears all bits in place. The size of the bitset and its allocated words are kept.
 func (bs *BitSet) ClearAll() {
-	bs.words = make([]uint64, len(bs.words))
+	clear(bs.words)
+}
+
+// Free clears all bits and replaces the words with a freshly allocated slice just large enough for
+// the size of the bitset, letting the old words be garbage collected.
+func (bs *BitSet) Free() {
+	bs.words = make([]uint64, max(1, (bs.size+63)/64))
+}
+
+// Reset clears all bits in place, keeping the size of the bitset and its allocated words so it can
+// be reused without reallocating. It's the same as ClearAll.
+func (bs *BitSet) Reset() {
+	bs.ClearAll()
+}
+
+// SetAll sets all bits in [0, bitset.size) to 1.
+func (bs *BitSet) SetAll() {
+	bitsLeft := bs.size
+	for i := range bs.words {
+		bs.words[i] = mask(^uint64(0), bitsLeft)
+		bitsLeft -= 64
+	}
 }
 
-// Flip flips the Nth bit, i.e. 0 -> 1 or 1 -> 0.
-func (bs *BitSet) Flip(n int) {
+// Flip flips the Nth bit, i.e. 0 -> 1 or 1 -> 0. If n >= bitset.size the bitset is grown to hold
+// it, so the bit ends up set. Negative n is ignored. Use FlipStrict to flip without growing.
+func (bs *BitSet) Flip(n int) *BitSet {
+	if n < 0 {
+		return bs
+	}
 	bs.resize(n)
 	bs.flip(n)
+	return bs
 }
 
 // FlipBits flips the bits at the given indices.
@@ -75,12 +470,24 @@ func (bs *BitSet) FlipBits(bits []int) {
 	}
 }
 
-// Test checks if the Nth bit is set to 1. Errors if n < 0 or n >= bitset.size
+// Test checks if the Nth bit is set to 1. Returns false if n < 0 or n >= bitset.size.
 func (bs *BitSet) Test(n int) bool {
+	if n < 0 || n >= bs.size {
+		return false
+	}
 	wordIdx, bitIdx := bs.getWordAndPos(n)
+	if wordIdx >= len(bs.words) {
+		return false
+	}
 	return bs.words[wordIdx]&(1<<bitIdx) >= 1
 }
 
+// Contains returns true if the Nth bit is set. It's the same as Test, so it returns false if n < 0
+// or n >= bitset.size.
+func (bs *BitSet) Contains(n int) bool {
+	return bs.Test(n)
+}
+
 // TestBits tests if multiple bits are set to 1. Returns a slice of bools that are true/false
 // if the corresponding bits are set and the number of set bits.
 func (bs *BitSet) TestBits(bits []int) ([]bool, int) {
@@ -95,6 +502,103 @@ func (bs *BitSet) TestBits(bits []int) ([]bool, int) {
 	return res, numSet
 }
 
+// TestAndSet sets the Nth bit to 1 and returns whether it was set before. It isn't atomic across
+// goroutines; use ConcurrentBitSet or external locking for that.
+func (bs *BitSet) TestAndSet(n int) bool {
+	prev := bs.Test(n)
+	bs.Set(n)
+	return prev
+}
+
+// TestAndClear zeroes the Nth bit and returns whether it was set before. It isn't atomic across
+// goroutines; use ConcurrentBitSet or external locking for that.
+func (bs *BitSet) TestAndClear(n int) bool {
+	prev := bs.Test(n)
+	bs.Clear(n)
+	return prev
+}
+
+// SetAndReport sets the Nth bit to 1 like Set and reports whether the bit changed.
+func (bs *BitSet) SetAndReport(n int) (changed bool) {
+	if n < 0 {
+		return false
+	}
+	return !bs.TestAndSet(n)
+}
+
+// ClearAndReport zeroes the Nth bit like Clear and reports whether the bit changed.
+func (bs *BitSet) ClearAndReport(n int) (changed bool) {
+	return bs.TestAndClear(n)
+}
+
+// FlipAndReport flips the Nth bit like Flip and reports whether the bit changed, which is always
+// the case unless n is negative.
+func (bs *BitSet) FlipAndReport(n int) (changed bool) {
+	if n < 0 {
+		return false
+	}
+	bs.Flip(n)
+	return true
+}
+
+// SwapBits exchanges the values of the Ith and Jth bits, growing the bitset if either is out of
+// range. Does nothing if either index is negative.
+func (bs *BitSet) SwapBits(i, j int) *BitSet {
+	if i < 0 || j < 0 {
+		return bs
+	}
+	bs.resize(max(i, j))
+	if bs.Test(i) != bs.Test(j) {
+		bs.flip(i)
+		bs.flip(j)
+	}
+	return bs
+}
+
+// SetE sets the Nth bit to 1. Unlike Set, it doesn't grow the bitset and errors if n < 0 or
+// n >= bitset.size.
+func (bs *BitSet) SetE(n int) error {
+	if err := bs.checkValidBit(n); err != nil {
+		return err
+	}
+	bs.set(n)
+	return nil
+}
+
+// ClearE zeroes the Nth bit. Unlike Clear, it doesn't grow the bitset and errors if n < 0 or
+// n >= bitset.size.
+func (bs *BitSet) ClearE(n int) error {
+	if err := bs.checkValidBit(n); err != nil {
+		return err
+	}
+	bs.clear(n)
+	return nil
+}
+
+// FlipE flips the Nth bit. Unlike Flip, it doesn't grow the bitset and errors if n < 0 or
+// n >= bitset.size.
+func (bs *BitSet) FlipE(n int) error {
+	if err := bs.checkValidBit(n); err != nil {
+		return err
+	}
+	bs.flip(n)
+	return nil
+}
+
+// FlipStrict flips the Nth bit only if it's in [0, bitset.size), erroring otherwise without growing
+// the bitset. It's the same as FlipE.
+func (bs *BitSet) FlipStrict(n int) error {
+	return bs.FlipE(n)
+}
+
+// TestE checks if the Nth bit is set to 1. Errors if n < 0 or n >= bitset.size.
+func (bs *BitSet) TestE(n int) (bool, error) {
+	if err := bs.checkValidBit(n); err != nil {
+		return false, err
+	}
+	return bs.Test(n), nil
+}
+
 // CountSetBits returns the number of set bits.
 func (bs *BitSet) CountSetBits() int {
 	count := 0
@@ -104,38 +608,592 @@ func (bs *BitSet) CountSetBits() int {
 	return count
 }
 
-// Or sets the bits of the receiver to the result of the receiver OR (|) other.
-func (bs *BitSet) Or(other *BitSet) {
+// Cardinality returns the number of set bits. It's the same as CountSetBits.
+func (bs *BitSet) Cardinality() int {
+	return bs.CountSetBits()
+}
+
+// parallelCountThreshold is the number of words below which CountSetBitsParallel counts serially,
+// since goroutine overhead outweighs the speedup for small bitsets.
+const parallelCountThreshold = 1 << 14
+
+// CountSetBitsParallel returns the number of set bits like CountSetBits, splitting the work across
+// goroutines for large bitsets.
+func (bs *BitSet) CountSetBitsParallel() int {
+	if len(bs.words) < parallelCountThreshold {
+		return bs.CountSetBits()
+	}
+	numChunks := runtime.GOMAXPROCS(0)
+	chunkSize := (len(bs.words) + numChunks - 1) / numChunks
+	counts := make([]int, numChunks)
+	wg := sync.WaitGroup{}
+	for c := range counts {
+		chunk := bs.words[min(c*chunkSize, len(bs.words)):min((c+1)*chunkSize, len(bs.words))]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			count := 0
+			for _, word := range chunk {
+				count += bits.OnesCount64(word)
+			}
+			counts[c] = count
+		}()
+	}
+	wg.Wait()
+	count := 0
+	for _, n := range counts {
+		count += n
+	}
+	return count
+}
+
+// CountClearBits returns the number of zero bits in [0, bitset.size). Bits beyond the size in the
+// final word aren't counted.
+func (bs *BitSet) CountClearBits() int {
+	return bs.size - bs.Rank(bs.size)
+}
+
+// CountMatching returns the number of bits in [0, bitset.size) that equal the corresponding bit of
+// pattern, with pattern repeated across every word. A pattern of 0 counts the clear bits and a
+// pattern of all ones counts the set bits.
+func (bs *BitSet) CountMatching(pattern uint64) int {
+	count, bitsLeft := 0, bs.size
+	for i := 0; i < len(bs.words) && bitsLeft > 0; i++ {
+		count += bits.OnesCount64(mask(^(bs.words[i] ^ pattern), bitsLeft))
+		bitsLeft -= 64
+	}
+	return count
+}
+
+// ToBoolSlice returns a slice of length bitset.size where element i is true if the Ith bit is set.
+func (bs *BitSet) ToBoolSlice() []bool {
+	res := make([]bool, bs.size)
+	for i := range res {
+		res[i] = bs.Test(i)
+	}
+	return res
+}
+
+// ToIndices returns the indices of the set bits in ascending order.
+func (bs *BitSet) ToIndices() []int {
+	indices := make([]int, 0, bs.CountSetBits())
+	for i, word := range bs.words {
+		for word != 0 {
+			indices = append(indices, i*64+bits.TrailingZeros64(word))
+			word &= word - 1
+		}
+	}
+	return indices
+}
+
+// DrainSetBits returns the indices of the set bits in ascending order and clears them, leaving the
+// bitset empty.
+func (bs *BitSet) DrainSetBits() []int {
+	indices := []int{}
+	for i, word := range bs.words {
+		for ; word != 0; word &= word - 1 {
+			indices = append(indices, i*64+bits.TrailingZeros64(word))
+		}
+		bs.words[i] = 0
+	}
+	return indices
+}
+
+// NextSetBit returns the index of the first set bit at or after from, or -1 if there is none.
+func (bs *BitSet) NextSetBit(from int) int {
+	if n := bs.nextBit(from, true); n < bs.size {
+		return n
+	}
+	return -1
+}
+
+// NextSetBits returns the indices of up to limit set bits at or after from, in ascending order.
+// Fewer than limit indices means there are no more set bits, so a bitset can be walked in chunks
+// by passing the last returned index plus one as the next from.
+func (bs *BitSet) NextSetBits(from, limit int) []int {
+	from = max(from, 0)
+	if limit <= 0 || from >= bs.size {
+		return nil
+	}
+	indices := make([]int, 0, min(limit, 64))
+	wordIdx, bitIdx := bs.getWordAndPos(from)
+	for ; wordIdx < len(bs.words); wordIdx, bitIdx = wordIdx+1, 0 {
+		word := bs.words[wordIdx] &^ ((1 << bitIdx) - 1)
+		for word != 0 {
+			indices = append(indices, wordIdx*64+bits.TrailingZeros64(word))
+			if len(indices) == limit {
+				return indices
+			}
+			word &= word - 1
+		}
+	}
+	return indices
+}
+
+// NextClearBit returns the index of the first clear bit in [from, bitset.size), or -1 if there is
+// none.
+func (bs *BitSet) NextClearBit(from int) int {
+	if n := bs.nextBit(from, false); n < bs.size {
+		return n
+	}
+	return -1
+}
+
+// Runs returns the [start, end) bounds of each maximal run of consecutive set bits in ascending
+// order.
+func (bs *BitSet) Runs() [][2]int {
+	runs := [][2]int{}
+	for start := bs.NextSetBit(0); start >= 0; {
+		end := bs.NextClearBit(start)
+		if end < 0 {
+			end = bs.size
+		}
+		runs = append(runs, [2]int{start, end})
+		start = bs.NextSetBit(end)
+	}
+	return runs
+}
+
+// Gaps returns the lengths of the runs of clear bits before the first set bit, between each pair
+// of consecutive set bits, and after the last set bit up to bitset.size. A bitset with k set bits
+// has k+1 gaps, some of which may be 0.
+func (bs *BitSet) Gaps() []int {
+	gaps, prev := []int{}, -1
+	for n := bs.NextSetBit(0); n >= 0; n = bs.NextSetBit(n + 1) {
+		gaps = append(gaps, n-prev-1)
+		prev = n
+	}
+	return append(gaps, bs.size-prev-1)
+}
+
+// PopFirst clears the lowest set bit and returns its index, or ok == false if no bits are set.
+func (bs *BitSet) PopFirst() (int, bool) {
+	n := bs.nextBit(0, true)
+	if n >= bs.size {
+		return 0, false
+	}
+	bs.clear(n)
+	return n, true
+}
+
+// PopLast clears the highest set bit and returns its index, or ok == false if no bits are set.
+func (bs *BitSet) PopLast() (int, bool) {
+	n := bs.LastSetBit()
+	if n < 0 {
+		return 0, false
+	}
+	bs.clear(n)
+	return n, true
+}
+
+// LastSetBit returns the index of the highest set bit, or -1 if no bits are set.
+func (bs *BitSet) LastSetBit() int {
+	for i := len(bs.words) - 1; i >= 0; i-- {
+		if bs.words[i] != 0 {
+			return i*64 + 63 - bits.LeadingZeros64(bs.words[i])
+		}
+	}
+	return -1
+}
+
+// Min returns the index of the lowest set bit. Ok is false if no bits are set.
+func (bs *BitSet) Min() (index int, ok bool) {
+	if index = bs.NextSetBit(0); index < 0 {
+		return 0, false
+	}
+	return index, true
+}
+
+// Max returns the index of the highest set bit. Ok is false if no bits are set.
+func (bs *BitSet) Max() (index int, ok bool) {
+	if index = bs.LastSetBit(); index < 0 {
+		return 0, false
+	}
+	return index, true
+}
+
+// TrailingZeros returns the number of clear bits below the lowest set bit, or bitset.size if no
+// bits are set.
+func (bs *BitSet) TrailingZeros() int {
+	for i, word := range bs.words {
+		if word != 0 {
+			return i*64 + bits.TrailingZeros64(word)
+		}
+	}
+	return bs.size
+}
+
+// LeadingZeros returns the number of clear bits in [0, bitset.size) above the highest set bit, or
+// bitset.size if no bits are set.
+func (bs *BitSet) LeadingZeros() int {
+	return bs.size - 1 - bs.LastSetBit()
+}
+
+// Density returns the fraction of bits that are set, in [0, 1]. Returns 0 for an empty bitset.
+func (bs *BitSet) Density() float64 {
+	if bs.size == 0 {
+		return 0
+	}
+	return float64(bs.CountSetBits()) / float64(bs.size)
+}
+
+// Rank returns the number of set bits strictly below the Ith bit, i.e. in [0, i). I is clamped
+// to [0, bitset.size].
+func (bs *BitSet) Rank(i int) int {
+	i = max(0, min(i, bs.size))
+	wordIdx, bitIdx := bs.getWordAndPos(i)
+	count := 0
+	for _, word := range bs.words[:min(wordIdx, len(bs.words))] {
+		count += bits.OnesCount64(word)
+	}
+	if bitIdx > 0 && wordIdx < len(bs.words) {
+		count += bits.OnesCount64(mask(bs.words[wordIdx], bitIdx))
+	}
+	return count
+}
+
+// Select returns the index of the Kth set bit, counting from 0, or -1 if fewer than k+1 bits are set.
+func (bs *BitSet) Select(k int) int {
+	if k < 0 {
+		return -1
+	}
+	for i, word := range bs.words {
+		count := bits.OnesCount64(word)
+		if k >= count {
+			k -= count
+			continue
+		}
+		for ; k > 0; k-- {
+			word &= word - 1
+		}
+		return i*64 + bits.TrailingZeros64(word)
+	}
+	return -1
+}
+
+// RandomSetBit returns the index of a set bit chosen uniformly at random using r, or ok == false
+// if no bits are set.
+func (bs *BitSet) RandomSetBit(r *rand.Rand) (int, bool) {
+	count := bs.CountSetBits()
+	if count == 0 {
+		return 0, false
+	}
+	return bs.Select(r.Intn(count)), true
+}
+
+// Or sets the bits of the receiver to the result of the receiver OR (|) other. The receiver is
+// grown to the size of other if other is larger.
+func (bs *BitSet) Or(other *BitSet) *BitSet {
+	if other.size > bs.size {
+		bs.resize(other.size - 1)
+	}
 	bitsLeft := bs.size
 	for i, j := 0, 0; i < len(bs.words) && j < len(other.words); i, j = i+1, j+1 {
 		bs.words[i] = mask(bs.words[i]|other.words[j], bitsLeft)
 		bitsLeft -= 64
 	}
+	return bs
 }
 
-// And sets the bits of the receiver to the result of the receiver AND (&) other.
-func (bs *BitSet) And(other *BitSet) {
+// And sets the bits of the receiver to the result of the receiver AND (&) other. Bits beyond the
+// words of other are cleared.
+func (bs *BitSet) And(other *BitSet) *BitSet {
 	bitsLeft := bs.size
 	for i, j := 0, 0; i < len(bs.words) && j < len(other.words); i, j = i+1, j+1 {
 		bs.words[i] = mask(bs.words[i]&other.words[j], bitsLeft)
 		bitsLeft -= 64
 	}
+	if len(bs.words) > len(other.words) {
+		clear(bs.words[len(other.words):])
+	}
+	return bs
 }
 
-// Xor sets the bits of the receiver to the result of the receiver AND (&) other.
-func (bs *BitSet) Xor(other *BitSet) {
+// Xor sets the bits of the receiver to the result of the receiver XOR (^) other. The receiver is
+// grown to the size of other if other is larger.
+func (bs *BitSet) Xor(other *BitSet) *BitSet {
+	if other.size > bs.size {
+		bs.resize(other.size - 1)
+	}
 	bitsLeft := bs.size
 	for i, j := 0, 0; i < len(bs.words) && j < len(other.words); i, j = i+1, j+1 {
 		bs.words[i] = mask(bs.words[i]^other.words[j], bitsLeft)
 		bitsLeft -= 64
 	}
+	return bs
+}
+
+// Nand sets the bits of the receiver to the result of NOT (receiver AND other). Bits beyond the
+// size of the receiver stay clear.
+func (bs *BitSet) Nand(other *BitSet) *BitSet {
+	bitsLeft := bs.size
+	for i := range bs.words {
+		bs.words[i] = mask(^(bs.words[i] & other.getWord(i)), bitsLeft)
+		bitsLeft -= 64
+	}
+	return bs
+}
+
+// Nor sets the bits of the receiver to the result of NOT (receiver OR other). Bits beyond the size
+// of the receiver stay clear.
+func (bs *BitSet) Nor(other *BitSet) *BitSet {
+	bitsLeft := bs.size
+	for i := range bs.words {
+		bs.words[i] = mask(^(bs.words[i] | other.getWord(i)), bitsLeft)
+		bitsLeft -= 64
+	}
+	return bs
+}
+
+// SymmetricDifference sets the bits of the receiver to those set in exactly one of the receiver and
+// other. It's the same as Xor.
+func (bs *BitSet) SymmetricDifference(other *BitSet) *BitSet {
+	return bs.Xor(other)
+}
+
+// AndNot sets the bits of the receiver to the result of the receiver AND NOT (&^) other, i.e. clears
+// the bits that are set in other.
+func (bs *BitSet) AndNot(other *BitSet) *BitSet {
+	for i := 0; i < min(len(bs.words), len(other.words)); i++ {
+		bs.words[i] &^= other.words[i]
+	}
+	return bs
 }
 
-// Not flips each bit of the bitset
-func (bs *BitSet) Not() {
+// Difference removes from the receiver the bits that are set in other, i.e. receiver &^ other. It's
+// the same as AndNot.
+func (bs *BitSet) Difference(other *BitSet) *BitSet {
+	return bs.AndNot(other)
+}
+
+// Op selects the operation Merge combines two bitsets with.
+type Op int
+
+const (
+	OpAnd    Op = iota // receiver & other, see And
+	OpOr               // receiver | other, see Or
+	OpXor              // receiver ^ other, see Xor
+	OpAndNot           // receiver &^ other, see AndNot
+)
+
+// Merge sets the bits of the receiver to the result of combining it with other using op, by
+// calling And, Or, Xor or AndNot. Panics if op isn't one of the Op constants.
+func (bs *BitSet) Merge(other *BitSet, op Op) *BitSet {
+	switch op {
+	case OpAnd:
+		return bs.And(other)
+	case OpOr:
+		return bs.Or(other)
+	case OpXor:
+		return bs.Xor(other)
+	case OpAndNot:
+		return bs.AndNot(other)
+	default:
+		panic(fmt.Sprintf("bitset: unknown Op %d", op))
+	}
+}
+
+// Not flips each bit of the bitset in [0, bitset.size).
+func (bs *BitSet) Not() *BitSet {
 	bitsLeft := bs.size
 	for i := range bs.words {
-		bs.words[i] = mask(^bs.words[i], bitsLeft%64)
+		bs.words[i] = mask(^bs.words[i], bitsLeft)
+		bitsLeft -= 64
+	}
+	return bs
+}
+
+// Complement flips each bit in [0, bitset.size), leaving bits beyond the size clear. It's the same
+// as Not.
+func (bs *BitSet) Complement() *BitSet {
+	return bs.Not()
+}
+
+// FlipAll flips exactly the bits in [0, bitset.size), leaving the unused bits of the final word
+// clear. It's the same as Not.
+func (bs *BitSet) FlipAll() *BitSet {
+	return bs.Not()
+}
+
+// ShiftLeft moves every bit up by n positions, i.e. bit i becomes bit i+n, growing the bitset by n
+// bits. Does nothing if n <= 0.
+func (bs *BitSet) ShiftLeft(n int) {
+	if n <= 0 {
+		return
+	}
+	bs.resize(bs.size + n - 1)
+	wordShift, bitShift := n/64, n%64
+	for i := len(bs.words) - 1; i >= 0; i-- {
+		src, word := i-wordShift, uint64(0)
+		if src >= 0 {
+			word = bs.words[src] << bitShift
+			if bitShift > 0 && src > 0 {
+				word |= bs.words[src-1] >> (64 - bitShift)
+			}
+		}
+		bs.words[i] = word
+	}
+}
+
+// ShiftRight moves every bit down by n positions, i.e. bit i becomes bit i-n, dropping bits that
+// fall below 0. The size of the bitset is unchanged. Does nothing if n <= 0.
+func (bs *BitSet) ShiftRight(n int) {
+	if n <= 0 {
+		return
+	}
+	wordShift, bitShift := n/64, n%64
+	for i := range bs.words {
+		src, word := i+wordShift, uint64(0)
+		if src < len(bs.words) {
+			word = bs.words[src] >> bitShift
+			if bitShift > 0 && src+1 < len(bs.words) {
+				word |= bs.words[src+1] << (64 - bitShift)
+			}
+		}
+		bs.words[i] = word
+	}
+}
+
+// InsertBit inserts a bit with value v at pos, moving every bit at or above pos up by one and
+// growing the bitset by one bit. If pos > bitset.size the bitset is first grown to pos bits.
+// Negative pos is ignored.
+func (bs *BitSet) InsertBit(pos int, v bool) *BitSet {
+	if pos < 0 {
+		return bs
+	}
+	bs.Grow(pos)
+	bs.resize(bs.size)
+	wordIdx, bitIdx := bs.getWordAndPos(pos)
+	for i := len(bs.words) - 1; i > wordIdx; i-- {
+		bs.words[i] = bs.words[i]<<1 | bs.words[i-1]>>63
+	}
+	low := mask(bs.words[wordIdx], bitIdx)
+	bs.words[wordIdx] = low | (bs.words[wordIdx]&^low)<<1
+	if v {
+		bs.set(pos)
+	} else {
+		bs.clear(pos)
+	}
+	return bs
+}
+
+// DeleteBit removes the bit at pos, moving every bit above pos down by one and shrinking the bitset
+// by one bit. Does nothing if pos < 0 or pos >= bitset.size.
+func (bs *BitSet) DeleteBit(pos int) *BitSet {
+	if pos < 0 || pos >= bs.size {
+		return bs
+	}
+	wordIdx, bitIdx := bs.getWordAndPos(pos)
+	low := mask(bs.words[wordIdx], bitIdx)
+	bs.words[wordIdx] = low | bs.words[wordIdx]>>(bitIdx+1)<<bitIdx | bs.getWord(wordIdx+1)<<63
+	for i := wordIdx + 1; i < len(bs.words); i++ {
+		bs.words[i] = bs.words[i]>>1 | bs.getWord(i+1)<<63
+	}
+	bs.size--
+	return bs
+}
+
+// Concat appends the bits of other after those of the receiver, i.e. bit i of other becomes bit
+// bitset.size+i, growing the receiver by other's size.
+func (bs *BitSet) Concat(other *BitSet) *BitSet {
+	if other.size == 0 {
+		return bs
+	}
+	offset, otherWords := bs.size, slices.Clone(other.words[:(other.size+63)/64])
+	bs.resize(offset + other.size - 1)
+	wordShift, bitShift := offset/64, offset%64
+	bitsLeft := other.size
+	for j, word := range otherWords {
+		word = mask(word, bitsLeft)
+		bitsLeft -= 64
+		bs.words[wordShift+j] |= word << bitShift
+		if bitShift > 0 && wordShift+j+1 < len(bs.words) {
+			bs.words[wordShift+j+1] |= word >> (64 - bitShift)
+		}
+	}
+	return bs
+}
+
+// SubSet returns a new bitset of size hi-lo holding the bits in [lo, hi), rebased so that bit lo
+// becomes bit 0. Lo is clamped to 0 and an empty bitset is returned if hi <= lo.
+func (bs *BitSet) SubSet(lo, hi int) *BitSet {
+	lo = max(lo, 0)
+	if hi <= lo {
+		return NewBitSetWithInitialSize(0)
+	}
+	res := NewBitSetWithInitialSize(hi - lo)
+	wordShift, bitShift := lo/64, lo%64
+	bitsLeft := res.size
+	for j := range res.words {
+		word := bs.getWord(wordShift+j) >> bitShift
+		if bitShift > 0 {
+			word |= bs.getWord(wordShift+j+1) << (64 - bitShift)
+		}
+		res.words[j] = mask(word, bitsLeft)
+		bitsLeft -= 64
+	}
+	return res
+}
+
+// RotateLeft rotates the bits within [0, bitset.size) up by n positions, i.e. bit i becomes bit
+// (i+n) mod size, wrapping the top bits around to the bottom. Negative n rotates right.
+func (bs *BitSet) RotateLeft(n int) *BitSet {
+	if bs.size == 0 {
+		return bs
+	}
+	if n %= bs.size; n < 0 {
+		n += bs.size
+	}
+	if n == 0 {
+		return bs
+	}
+	res := bs.SubSet(bs.size-n, bs.size).Concat(bs.SubSet(0, bs.size-n))
+	res.CopyInto(bs)
+	return bs
+}
+
+// RotateRight rotates the bits within [0, bitset.size) down by n positions, i.e. bit i becomes bit
+// (i-n) mod size, wrapping the bottom bits around to the top. Negative n rotates left.
+func (bs *BitSet) RotateRight(n int) *BitSet {
+	if bs.size == 0 {
+		return bs
+	}
+	return bs.RotateLeft(-(n % bs.size))
+}
+
+// Reverse reverses the order of the bits in [0, bitset.size), i.e. bit i becomes bit size-1-i.
+func (bs *BitSet) Reverse() {
+	numWords := (bs.size + 63) / 64
+	words := bs.words[:numWords]
+	slices.Reverse(words)
+	for i := range words {
+		words[i] = bits.Reverse64(words[i])
+	}
+	bs.ShiftRight(numWords*64 - bs.size)
+}
+
+// ForEachWord replaces each 64-bit word of the bitset, in ascending order, with the result of
+// calling fn with the word's index and value. Bits the result sets beyond bitset.size are cleared.
+func (bs *BitSet) ForEachWord(fn func(wordIndex int, word uint64) uint64) {
+	bitsLeft := bs.size
+	for i, word := range bs.words {
+		bs.words[i] = mask(fn(i, word), bitsLeft)
+		bitsLeft -= 64
+	}
+}
+
+// ForEachClearBit calls fn with the index of each clear bit in [0, bitset.size), in ascending order,
+// stopping early if fn returns false.
+func (bs *BitSet) ForEachClearBit(fn func(index int) bool) {
+	bitsLeft := bs.size
+	for i := 0; i < len(bs.words) && bitsLeft > 0; i++ {
+		word := mask(^bs.words[i], bitsLeft)
+		for word != 0 {
+			if !fn(i*64 + bits.TrailingZeros64(word)) {
+				return
+			}
+			word &= word - 1
+		}
 		bitsLeft -= 64
 	}
 }
@@ -160,6 +1218,128 @@ func (bs *BitSet) None() bool {
 	return true
 }
 
+// IsEmpty returns true if no bits are set. It's the same as None.
+func (bs *BitSet) IsEmpty() bool {
+	return bs.None()
+}
+
+// Equal returns true if the receiver and other have the same bits set. Their sizes and any trailing
+// zero words are ignored.
+func (bs *BitSet) Equal(other *BitSet) bool {
+	for i := 0; i < max(len(bs.words), len(other.words)); i++ {
+		if bs.getWord(i) != other.getWord(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// EqualStrict returns true if the receiver and other have the same size and the same bits set.
+// Unlike Equal, a size-64 bitset never equals a size-128 one.
+func (bs *BitSet) EqualStrict(other *BitSet) bool {
+	return bs.size == other.size && bs.Equal(other)
+}
+
+// Relation is the result of Compare, describing how two bitsets' set bits relate. Apart from
+// RelIncomparable its sign is meaningful: negative for a subset, zero for equal and positive for a
+// superset.
+type Relation int
+
+const (
+	RelSubset       Relation = -1 // the receiver is a proper subset of other
+	RelEqual        Relation = 0  // both have the same bits set
+	RelSuperset     Relation = 1  // the receiver is a proper superset of other
+	RelIncomparable Relation = 2  // neither contains the other
+)
+
+// Compare returns how the set bits of the receiver relate to those of other: RelSubset (negative)
+// if it's a proper subset, RelEqual (zero) if they're equal, RelSuperset (positive) if it's a
+// proper superset and RelIncomparable if neither contains the other. Check for RelIncomparable
+// before testing the sign. Like Equal, sizes are ignored.
+func (bs *BitSet) Compare(other *BitSet) Relation {
+	subset, superset := true, true
+	for i := 0; i < max(len(bs.words), len(other.words)); i++ {
+		a, b := bs.getWord(i), other.getWord(i)
+		if a&^b != 0 {
+			subset = false
+		}
+		if b&^a != 0 {
+			superset = false
+		}
+	}
+	switch {
+	case subset && superset:
+		return RelEqual
+	case subset:
+		return RelSubset
+	case superset:
+		return RelSuperset
+	default:
+		return RelIncomparable
+	}
+}
+
+// Key returns a string usable as a map key, such that bitsets that are Equal have the same key
+// regardless of their sizes. It holds the words up to the highest set bit as little-endian bytes.
+// It isn't a cryptographic hash.
+func (bs *BitSet) Key() string {
+	numWords := (bs.LastSetBit() + 64) / 64
+	b := make([]byte, 0, 8*numWords)
+	for _, word := range bs.words[:numWords] {
+		b = binary.LittleEndian.AppendUint64(b, word)
+	}
+	return string(b)
+}
+
+// JaccardSimilarity returns |A∩B| / |A∪B| for the receiver A and other B. Returns 1 if both
+// bitsets are empty.
+func (bs *BitSet) JaccardSimilarity(other *BitSet) float64 {
+	intersection, union := 0, 0
+	for i := 0; i < max(len(bs.words), len(other.words)); i++ {
+		a, b := bs.getWord(i), other.getWord(i)
+		intersection += bits.OnesCount64(a & b)
+		union += bits.OnesCount64(a | b)
+	}
+	if union == 0 {
+		return 1
+	}
+	return float64(intersection) / float64(union)
+}
+
+// CountAnd returns the number of set bits in the receiver AND (&) other without allocating.
+func (bs *BitSet) CountAnd(other *BitSet) int {
+	count := 0
+	for i := 0; i < min(len(bs.words), len(other.words)); i++ {
+		count += bits.OnesCount64(bs.words[i] & other.words[i])
+	}
+	return count
+}
+
+// CountOr returns the number of set bits in the receiver OR (|) other without allocating.
+func (bs *BitSet) CountOr(other *BitSet) int {
+	count := 0
+	for i := 0; i < max(len(bs.words), len(other.words)); i++ {
+		count += bits.OnesCount64(bs.getWord(i) | other.getWord(i))
+	}
+	return count
+}
+
+// CountXor returns the number of set bits in the receiver XOR (^) other without allocating.
+func (bs *BitSet) CountXor(other *BitSet) int {
+	count := 0
+	for i := 0; i < max(len(bs.words), len(other.words)); i++ {
+		count += bits.OnesCount64(bs.getWord(i) ^ other.getWord(i))
+	}
+	return count
+}
+
+// Diff returns the Hamming distance between the receiver and other, i.e. the number of bits set
+// in exactly one of them, and whether they're Equal, in a single pass.
+func (bs *BitSet) Diff(other *BitSet) (distance int, equal bool) {
+	distance = bs.CountXor(other)
+	return distance, distance == 0
+}
+
 // Or returns the result of bitset OR (|) other. The result's size will be equal to that of the
 // larger bitset.
 func Or(bs1 *BitSet, bs2 *BitSet) *BitSet {
@@ -168,21 +1348,22 @@ func Or(bs1 *BitSet, bs2 *BitSet) *BitSet {
 		smallerSet, largerSet = bs2, bs1
 	}
 	newBitArray := make([]uint64, len(largerSet.words))
-	for i := len(smallerSet.words) - 1; i >= 0; i-- {
-		newBitArray[i] = smallerSet.words[i] | largerSet.words[i]
+	copy(newBitArray, largerSet.words)
+	for i := min(len(smallerSet.words), len(largerSet.words)) - 1; i >= 0; i-- {
+		newBitArray[i] |= smallerSet.words[i]
 	}
 	return &BitSet{size: largerSet.size, words: newBitArray}
 }
 
 // And returns the result of bitset AND (&) other. The result's size will be equal to that of the
-// larger bitset.
+// larger bitset. Words beyond the smaller bitset are left zeroed since they can't survive the AND.
 func And(bs1 *BitSet, bs2 *BitSet) *BitSet {
 	smallerSet, largerSet := bs1, bs2
 	if bs1.size > bs2.size {
 		smallerSet, largerSet = bs2, bs1
 	}
 	newBitArray := make([]uint64, len(largerSet.words))
-	for i := len(smallerSet.words) - 1; i >= 0; i-- {
+	for i := min(len(smallerSet.words), len(largerSet.words)) - 1; i >= 0; i-- {
 		newBitArray[i] = smallerSet.words[i] & largerSet.words[i]
 	}
 	return &BitSet{size: largerSet.size, words: newBitArray}
@@ -196,28 +1377,193 @@ func Xor(bs1 *BitSet, bs2 *BitSet) *BitSet {
 		smallerSet, largerSet = bs2, bs1
 	}
 	newBitArray := make([]uint64, len(largerSet.words))
-	for i := len(smallerSet.words) - 1; i >= 0; i-- {
-		newBitArray[i] = smallerSet.words[i] ^ largerSet.words[i]
+	copy(newBitArray, largerSet.words)
+	for i := min(len(smallerSet.words), len(largerSet.words)) - 1; i >= 0; i-- {
+		newBitArray[i] ^= smallerSet.words[i]
 	}
 	return &BitSet{size: largerSet.size, words: newBitArray}
 }
 
 // Not returns a new bitset obtained from flipping each bit of the input bitset.
 func Not(bs *BitSet) *BitSet {
-	newBitArray := make([]uint64, len(bs.words))
+	newBitArray, bitsLeft := make([]uint64, len(bs.words)), bs.size
 	for i := range bs.words {
-		newBitArray[i] = ^bs.words[i]
+		newBitArray[i] = mask(^bs.words[i], bitsLeft)
+		bitsLeft -= 64
 	}
 	return &BitSet{size: bs.size, words: newBitArray}
 }
 
-// Strings returns the representation of the bitset as a binary string.
+// Difference returns a new bitset with the bits of a that aren't set in b, i.e. a &^ b. The result's
+// size will be equal to that of a.
+func Difference(a, b *BitSet) *BitSet {
+	return a.Clone().AndNot(b)
+}
+
+// Equal returns true if a and b have the same bits set, like a.Equal(b).
+func Equal(a, b *BitSet) bool {
+	return a.Equal(b)
+}
+
+// OrInto stores the result of a OR (|) b into dst, reusing dst's words when they have enough
+// capacity. Dst's size will be equal to that of the larger bitset. Dst may be a or b.
+func OrInto(dst, a, b *BitSet) {
+	combineInto(dst, a, b, func(x, y uint64) uint64 { return x | y })
+}
+
+// AndInto stores the result of a AND (&) b into dst, reusing dst's words when they have enough
+// capacity. Dst's size will be equal to that of the larger bitset. Dst may be a or b.
+func AndInto(dst, a, b *BitSet) {
+	combineInto(dst, a, b, func(x, y uint64) uint64 { return x & y })
+}
+
+// XorInto stores the result of a XOR (^) b into dst, reusing dst's words when they have enough
+// capacity. Dst's size will be equal to that of the larger bitset. Dst may be a or b.
+func XorInto(dst, a, b *BitSet) {
+	combineInto(dst, a, b, func(x, y uint64) uint64 { return x ^ y })
+}
+
+// combineInto stores op applied to each pair of words of a and b into dst.
+func combineInto(dst, a, b *BitSet, op func(x, y uint64) uint64) {
+	size, numWords := max(a.size, b.size), max(len(a.words), len(b.words))
+	words := dst.words[:cap(dst.words)]
+	if len(words) < numWords {
+		words = make([]uint64, numWords)
+	}
+	// each word only depends on the words at the same index, so computing in place is safe even if
+	// dst aliases a or b
+	for i := range numWords {
+		words[i] = op(a.getWord(i), b.getWord(i))
+	}
+	clear(words[numWords:])
+	dst.size, dst.words = size, words[:numWords]
+}
+
+// Union returns the result of OR-ing (|) all the given bitsets. The result's size will be equal
+// to that of the largest bitset. Returns an empty bitset if no bitsets are given.
+func Union(sets ...*BitSet) *BitSet {
+	size, numWords := 0, 0
+	for _, bs := range sets {
+		size, numWords = max(size, bs.size), max(numWords, len(bs.words))
+	}
+	newBitArray := make([]uint64, numWords)
+	for _, bs := range sets {
+		for i, word := range bs.words {
+			newBitArray[i] |= word
+		}
+	}
+	return &BitSet{size: size, words: newBitArray}
+}
+
+// Intersection returns the result of AND-ing (&) all the given bitsets. The result's size will be
+// equal to that of the smallest bitset. Returns an empty bitset if no bitsets are given and a clone
+// if only one is given.
+func Intersection(sets ...*BitSet) *BitSet {
+	if len(sets) == 0 {
+		return NewBitSetWithInitialSize(0)
+	}
+	size, numWords := sets[0].size, len(sets[0].words)
+	for _, bs := range sets[1:] {
+		size, numWords = min(size, bs.size), min(numWords, len(bs.words))
+	}
+	newBitArray := slices.Clone(sets[0].words[:numWords])
+	for _, bs := range sets[1:] {
+		for i := range newBitArray {
+			newBitArray[i] &= bs.words[i]
+		}
+	}
+	return &BitSet{size: size, words: newBitArray}
+}
+
+// String returns the representation of the bitset as a binary string with leading zeros trimmed,
+// so the last character is bit 0. A bitset with no bits set is "0". Use StringN for a fixed width.
 func (bs *BitSet) String() string {
 	buffer := bytes.Buffer{}
 	for i := len(bs.words) - 1; i >= 0; i-- {
 		buffer.WriteString(fmt.Sprintf("%.64b", bs.words[i]))
 	}
-	return strings.TrimLeft(buffer.String(), "0")
+	if str := strings.TrimLeft(buffer.String(), "0"); str != "" {
+		return str
+	}
+	return "0"
+}
+
+// StringN returns the representation of the bitset as a binary string of exactly bitset.size
+// characters, zero-padded on the left. Like String, the last character is bit 0, so bit i is at
+// position size-1-i.
+func (bs *BitSet) StringN() string {
+	buffer := strings.Builder{}
+	buffer.Grow(bs.size)
+	for i := bs.size - 1; i >= 0; i-- {
+		if bs.Test(i) {
+			buffer.WriteByte('1')
+		} else {
+			buffer.WriteByte('0')
+		}
+	}
+	return buffer.String()
+}
+
+// StringGrouped returns the output of StringN split into groups of groupSize characters joined by
+// sep, e.g. "1010 0011". Groups are counted from bit 0, so when the size isn't a multiple of
+// groupSize the leftmost group is the short one. If groupSize <= 0 it's the same as StringN.
+func (bs *BitSet) StringGrouped(groupSize int, sep string) string {
+	str := bs.StringN()
+	if groupSize <= 0 || len(str) <= groupSize {
+		return str
+	}
+	buffer := strings.Builder{}
+	buffer.Grow(len(str) + (len(str)-1)/groupSize*len(sep))
+	first := len(str) % groupSize
+	if first == 0 {
+		first = groupSize
+	}
+	buffer.WriteString(str[:first])
+	for i := first; i < len(str); i += groupSize {
+		buffer.WriteString(sep)
+		buffer.WriteString(str[i : i+groupSize])
+	}
+	return buffer.String()
+}
+
+// ToHex returns the bitset as a lowercase hex string of its bits packed into little-endian bytes,
+// i.e. the first byte holds bits 0-7. Enough bytes are emitted to hold bitset.size bits.
+func (bs *BitSet) ToHex() string {
+	b := make([]byte, (bs.size+7)/8)
+	for i := range b {
+		b[i] = byte(bs.getWord(i/8) >> (8 * (i % 8)))
+	}
+	return hex.EncodeToString(b)
+}
+
+// Format implements fmt.Formatter. %b prints the bitset as a binary string like String, %x and %X
+// print its words in hex, and %s and %v print the indices of the set bits. The + flag prefixes
+// the output with the size of the bitset, e.g. "128:[0 5]".
+func (bs *BitSet) Format(f fmt.State, verb rune) {
+	if f.Flag('+') {
+		fmt.Fprintf(f, "%d:", bs.size)
+	}
+	switch verb {
+	case 'b':
+		fmt.Fprint(f, bs.String())
+	case 'x', 'X':
+		buffer := bytes.Buffer{}
+		for i := len(bs.words) - 1; i >= 0; i-- {
+			buffer.WriteString(fmt.Sprintf("%016x", bs.words[i]))
+		}
+		hex := strings.TrimLeft(buffer.String(), "0")
+		if hex == "" {
+			hex = "0"
+		}
+		if verb == 'X' {
+			hex = strings.ToUpper(hex)
+		}
+		fmt.Fprint(f, hex)
+	case 's', 'v':
+		fmt.Fprint(f, bs.ToIndices())
+	default:
+		fmt.Fprintf(f, "%%!%c(*bitset.BitSet=%s)", verb, bs.String())
+	}
 }
 
 // set sets the Nth bit to 1.
@@ -238,22 +1584,67 @@ func (bs *BitSet) flip(n int) {
 	bs.words[wordIdx] ^= 1 << bitIdx
 }
 
+// getWord returns the Ith word, or 0 if i is beyond the words the bitset holds.
+func (bs *BitSet) getWord(i int) uint64 {
+	if i < 0 || i >= len(bs.words) {
+		return 0
+	}
+	return bs.words[i]
+}
+
+// rangeWords calls fn with the index of each word overlapping [lo, hi) and a mask of the bits of
+// that word inside the range. Requires 0 <= lo < hi.
+func (bs *BitSet) rangeWords(lo, hi int, fn func(wordIdx int, m uint64)) {
+	for wordIdx := lo / 64; wordIdx <= (hi-1)/64; wordIdx++ {
+		start, end := max(lo-wordIdx*64, 0), min(hi-wordIdx*64, 64)
+		fn(wordIdx, mask(^uint64(0), end)&^mask(^uint64(0), start))
+	}
+}
+
+// nextBit returns the index of the first bit at or after from that is set if set is true or clear
+// otherwise, or bitset.size if there is none.
+func (bs *BitSet) nextBit(from int, set bool) int {
+	from = max(from, 0)
+	if from >= bs.size {
+		return bs.size
+	}
+	wordIdx, bitIdx := bs.getWordAndPos(from)
+	for ; wordIdx < len(bs.words); wordIdx, bitIdx = wordIdx+1, 0 {
+		word := bs.words[wordIdx]
+		if !set {
+			word = ^word
+		}
+		if word &^= (1 << bitIdx) - 1; word != 0 {
+			return min(wordIdx*64+bits.TrailingZeros64(word), bs.size)
+		}
+	}
+	return bs.size
+}
+
 func (bs *BitSet) getWordAndPos(n int) (int, int) {
 	return n / 64, n % 64
 }
 
-func (bs *BitSet) resize(newSize int) {
-	if newSize >= bs.size {
-		bs.size = newSize
-		numWords := 1 + int(float64(newSize)/64.0)
-		newNewWords := numWords - len(bs.words)
-		bs.words = append(bs.words, make([]uint64, 2*newNewWords)...)
+// resize grows the bitset so that the Nth bit is in range, i.e. size becomes n+1 and words holds
+// at least n/64+1 words, as decided by the growth policy. Does nothing if n is already in range.
+func (bs *BitSet) resize(n int) {
+	if n < bs.size {
+		return
+	}
+	bs.size = n + 1
+	if numWords := n/64 + 1; numWords > len(bs.words) {
+		policy := bs.growth
+		if policy == nil {
+			policy = AmortizedGrowth
+		}
+		numWords = max(numWords, policy(len(bs.words), numWords))
+		bs.words = append(bs.words, make([]uint64, numWords-len(bs.words))...)
 	}
 }
 
 func (bs *BitSet) checkValidBit(n int) error {
 	if n < 0 {
-		return fmt.Errorf("test: n must be >= 0")
+		return fmt.Errorf("bit index %d is negative for bitset of size %d", n, bs.size)
 	}
 	if n >= bs.size {
 		return fmt.Errorf("bit index %d out of range of bitset of size %d", n, bs.size)
@@ -262,9 +1653,12 @@ func (bs *BitSet) checkValidBit(n int) error {
 }
 
 // mask retains the first n bits of a word and zeroes out the rest, returning the result.
-// If n is invalid the original word is returned.
+// If n <= 0 the result is 0, and if n >= 64 the original word is returned.
 func mask(word uint64, n int) uint64 {
-	if n <= 0 || n >= 64 {
+	if n <= 0 {
+		return 0
+	}
+	if n >= 64 {
 		return word
 	}
 	return word & ((1 << n) - 1)
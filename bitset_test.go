@@ -3,8 +3,10 @@ package bitset
 import (
 	"fmt"
 	"math"
+	"math/bits"
 	"math/rand"
 	"slices"
+	"strings"
 	"testing"
 )
 
@@ -17,6 +19,14 @@ func TestBitSet_BitArrayLenDivisibleBy64(t *testing.T) {
 	}
 }
 
+func TestBitSet_BitArrayLen64(t *testing.T) {
+	for _, tt := range [][2]int{{0, 1}, {1, 1}, {63, 1}, {64, 1}, {65, 2}, {128, 2}, {1 << 20, 1 << 14}} {
+		if bs := NewBitSetWithInitialSize(tt[0]); len(bs.words) != tt[1] {
+			t.Errorf("NewBitSetWithInitialSize(%d) has length %d, want %d", tt[0], len(bs.words), tt[1])
+		}
+	}
+}
+
 func TestBitSet_BitArrayLenNotDivisibleBy64(t *testing.T) {
 	numBits := 512
 	bs := NewBitSetWithInitialSize(numBits + 1)
@@ -31,6 +41,62 @@ func TestBitSet_BitArrayLenNotDivisibleBy64(t *testing.T) {
 	}
 }
 
+func TestNewBitSetFromWords(t *testing.T) {
+	words := []uint64{0b101, 1 << 63}
+	bs := NewBitSetFromWords(words, 128)
+
+	if bs.Size() != 128 || !slices.Equal(bs.ToIndices(), []int{0, 2, 127}) {
+		t.Errorf("NewBitSetFromWords() == %v (size %d), want [0 2 127] (size 128)", bs.ToIndices(), bs.Size())
+	}
+	bs.Set(1)
+	if words[0] != 0b111 {
+		t.Errorf("NewBitSetFromWords() copied the words, want them adopted")
+	}
+
+	if bs := NewBitSetFromWords([]uint64{0b11, 0}, 2); bs.CountClearBits() != 0 || bs.CountSetBits() != 2 {
+		t.Errorf("NewBitSetFromWords() with all bits below size set: %v (size %d)", bs.ToIndices(), bs.Size())
+	}
+
+	tests := []struct {
+		name  string
+		words []uint64
+		size  int
+	}{
+		{"size 129 for 2 words", []uint64{0, 0}, 129},
+		{"negative size", []uint64{0}, -1},
+		{"bits above size 10", []uint64{^uint64(0)}, 10},
+		{"bit 10 with size 10", []uint64{1 << 10}, 10},
+		{"a set word beyond size 64", []uint64{1, 1}, 64},
+		{"bits with size 0", []uint64{1}, 0},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewBitSetFromWords() with %s didn't panic", tt.name)
+				}
+			}()
+			NewBitSetFromWords(tt.words, tt.size)
+		}()
+	}
+}
+
+func TestNewBitSetWithInitialSize_NonPositive(t *testing.T) {
+	for _, numBits := range []int{-1, -500, 0} {
+		bs := NewBitSetWithInitialSize(numBits)
+		if bs.Size() != 0 || len(bs.words) == 0 || bs.Any() {
+			t.Errorf("NewBitSetWithInitialSize(%d): Size() == %d, len(words) == %d, want 0, >= 1", numBits, bs.Size(), len(bs.words))
+		}
+		if bs.Test(0) || bs.CountClearBits() != 0 || bs.String() != "0" {
+			t.Errorf("NewBitSetWithInitialSize(%d) isn't an empty bitset: %v", numBits, bs.ToIndices())
+		}
+		bs.Set(3)
+		if bs.Size() != 4 || !slices.Equal(bs.ToIndices(), []int{3}) {
+			t.Errorf("NewBitSetWithInitialSize(%d) then Set(3) == %v (size %d), want [3] (size 4)", numBits, bs.ToIndices(), bs.Size())
+		}
+	}
+}
+
 func TestBitSet_Test(t *testing.T) {
 	words := []uint64{uint64(math.Pow(2.0, 63.0)) + 1}
 	// intializing bitset to binary representation of 2^63 + 1, so bits 0 and 63 should be set
@@ -56,6 +122,46 @@ func TestBitSet_Test(t *testing.T) {
 	bs.Set(-1)
 }
 
+func TestBitSet_Test_OutOfRange(t *testing.T) {
+	bs := NewBitSetWithInitialSize(64)
+	bs.SetBits([]int{0, 63})
+
+	if bs.Test(-1) {
+		t.Errorf("BitSet.Test(-1) == true, want false")
+	}
+	if bs.Test(bs.Size()) {
+		t.Errorf("BitSet.Test(%d) == true, want false", bs.Size())
+	}
+	if bs.Test(math.MaxInt) {
+		t.Errorf("BitSet.Test(%d) == true, want false", math.MaxInt)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { bs.Test(-1); bs.Test(math.MaxInt) }); allocs != 0 {
+		t.Errorf("BitSet.Test() out of range allocates %v times, want 0", allocs)
+	}
+}
+
+func TestBitSet_Contains(t *testing.T) {
+	bs := NewBitSetWithInitialSize(64)
+	bs.SetBits([]int{0, 63})
+
+	tests := []struct {
+		n    int
+		want bool
+	}{
+		{0, true},
+		{63, true},
+		{30, false},
+		{-1, false},
+		{64, false},
+		{math.MaxInt, false},
+	}
+	for _, tt := range tests {
+		if got := bs.Contains(tt.n); got != tt.want {
+			t.Errorf("BitSet.Contains(%d) == %v, want %v", tt.n, got, tt.want)
+		}
+	}
+}
+
 func TestBitSet_TestBits(t *testing.T) {
 	words := []uint64{uint64(math.Pow(2.0, 63.0)) + uint64(math.Pow(2.0, 30.0)) + 1}
 	// intializing bitset to binary representation of 2^63 + 2^30 + 1, so bits 0, 30, and 63 should be set
@@ -74,6 +180,46 @@ func TestBitSet_TestBits(t *testing.T) {
 	}
 }
 
+func TestBitSet_Clone(t *testing.T) {
+	bs := NewBitSetWithInitialSize(100)
+	bs.SetBits([]int{3, 70})
+	clone := bs.Clone()
+	clone.Set(5)
+
+	if clone.Size() != bs.Size() || !slices.Equal(clone.ToIndices(), []int{3, 5, 70}) {
+		t.Errorf("BitSet.Clone() == %v, want [3 5 70]", clone.ToIndices())
+	}
+	if bs.Test(5) {
+		t.Errorf("BitSet.Clone(): mutating the clone changed the original")
+	}
+}
+
+func TestBitSet_CopyInto(t *testing.T) {
+	bs := NewBitSetWithInitialSize(100)
+	bs.SetBits([]int{3, 70})
+	dst := NewBitSetWithInitialSize(1000)
+	dst.SetAll()
+	words := dst.words
+
+	bs.CopyInto(dst)
+	if dst.Size() != 100 || !slices.Equal(dst.ToIndices(), []int{3, 70}) {
+		t.Errorf("BitSet.CopyInto() == %v (size %d), want [3 70] (size 100)", dst.ToIndices(), dst.Size())
+	}
+	if &dst.words[0] != &words[0] {
+		t.Errorf("BitSet.CopyInto() reallocated dst's words despite enough capacity")
+	}
+	dst.Grow(1000)
+	if !slices.Equal(dst.ToIndices(), []int{3, 70}) {
+		t.Errorf("BitSet.CopyInto() left high bits set: %v after Grow", dst.ToIndices())
+	}
+
+	small := NewBitSetWithInitialSize(0)
+	bs.CopyInto(small)
+	if small.Size() != 100 || !small.Equal(bs) {
+		t.Errorf("BitSet.CopyInto() into smaller dst == %v (size %d), want [3 70] (size 100)", small.ToIndices(), small.Size())
+	}
+}
+
 func TestBitSet_Set(t *testing.T) {
 	bs := NewBitSetWithInitialSize(64)
 
@@ -99,215 +245,1178 @@ func TestBitSet_Set(t *testing.T) {
 	}
 }
 
-func TestBitSet_SetBits(t *testing.T) {
+func TestBitSet_Set_Grows(t *testing.T) {
 	bs := NewBitSetWithInitialSize(64)
-	bitsToSet := []int{0, 63, 0, 5, 10}
-	bs.SetBits(bitsToSet)
-	bools, numSet := bs.TestBits(bitsToSet)
+	bs.Set(130)
 
-	if numSet != len(bitsToSet) {
-		t.Errorf("BitSet.TestBits() want %d, got %d", len(bitsToSet), numSet)
+	if len(bs.words) < 3 {
+		t.Errorf("BitSet.Set(130) on 64-bit set: len(words) == %d, want >= 3", len(bs.words))
 	}
-	if !slices.Equal(bools, []bool{true, true, true, true, true}) {
-		t.Errorf("BitSet.TestBits() want [true, false, true, false, true], got %v", bools)
+	if bs.Size() != 131 {
+		t.Errorf("BitSet.Set(130) on 64-bit set: Size() == %d, want 131", bs.Size())
+	}
+	if !bs.Test(130) {
+		t.Errorf("BitSet.Test(130) == false, want true")
 	}
 }
 
-func TestBitSet_Clear(t *testing.T) {
-	words := []uint64{uint64(math.Pow(2.0, 63.0)) + 1}
-	// intializing bitset to binary representation of 2^63 + 1, so bits 0 and 63 should be set
-	bs := &BitSet{size: 64, words: words}
-
-	bs.Clear(64)
-
-	bs.Clear(-1)
+func TestBitSet_SetGrowthPolicy(t *testing.T) {
+	bs := NewBitSetWithInitialSize(64).SetGrowthPolicy(ExactGrowth)
+	for _, n := range []int{130, 131, 700, 64*20 + 5} {
+		bs.Set(n)
+		if want := n/64 + 1; len(bs.words) != want {
+			t.Errorf("BitSet.Set(%d) with ExactGrowth: len(words) == %d, want %d", n, len(bs.words), want)
+		}
+	}
+	clone := bs.Clone()
+	clone.Set(64 * 30)
+	if len(clone.words) != 31 {
+		t.Errorf("BitSet.Clone() didn't keep the growth policy: len(words) == %d, want 31", len(clone.words))
+	}
 
-	bs.Clear(0)
-	isSet := bs.Test(0)
-	if isSet {
-		t.Errorf("BitSet.Test(0) == true, want false")
+	bs = NewBitSetWithInitialSize(64)
+	for i := 0; i < 100; i++ {
+		bs.Set(i * 64)
+	}
+	if len(bs.words) < 100 || len(bs.words) >= 200 {
+		t.Errorf("default policy grew to %d words for 100 needed, want between 100 and 199", len(bs.words))
+	}
+	if bs.CountSetBits() != 100 || bs.Size() != 64*99+1 {
+		t.Errorf("default policy: %d bits set, size %d, want 100 bits set, size %d", bs.CountSetBits(), bs.Size(), 64*99+1)
 	}
 
-	bs.Clear(63)
-	isSet = bs.Test(63)
-	if isSet {
-		t.Errorf("BitSet.Test(63) == true, want false")
+	calls := 0
+	bs = NewBitSetWithInitialSize(64).SetGrowthPolicy(func(current, needed int) int {
+		calls++
+		return needed + 10
+	})
+	bs.Set(200)
+	bs.Set(64 * 12)
+	if calls != 1 || len(bs.words) != 14 {
+		t.Errorf("custom policy: %d calls, len(words) == %d, want 1 call, 14 words", calls, len(bs.words))
 	}
+	bs.SetGrowthPolicy(nil).Set(64 * 14)
+	if len(bs.words) != 28 {
+		t.Errorf("BitSet.SetGrowthPolicy(nil): len(words) == %d, want 28", len(bs.words))
+	}
+}
 
-	bs.Clear(30)
-	isSet = bs.Test(30)
-	if isSet {
-		t.Errorf("BitSet.Test(0) on zero bit == true, want false")
+func TestBitSet_Words(t *testing.T) {
+	bs := NewBitSetWithInitialSize(128)
+	bs.SetBits([]int{1, 65})
+
+	words := bs.Words()
+	if len(words) != bs.WordCount() || bs.WordCount() != len(bs.words) {
+		t.Errorf("BitSet.Words() has length %d, WordCount() == %d, want %d", len(words), bs.WordCount(), len(bs.words))
+	}
+	if words[0] != 2 || words[1] != 2 {
+		t.Errorf("BitSet.Words() == %v, want [2 2 ...]", words)
 	}
 
-	bs.Clear(0)
-	isSet = bs.Test(0)
-	if isSet {
-		t.Errorf("BitSet.Test(0) on cleared bit == true, want false")
+	words[0] = ^uint64(0)
+	if !slices.Equal(bs.ToIndices(), []int{1, 65}) {
+		t.Errorf("BitSet.Words(): mutating the returned slice changed the bitset to %v", bs.ToIndices())
 	}
 }
 
-func TestBitSet_ClearBits(t *testing.T) {
-	words := []uint64{uint64(math.Pow(2.0, 63.0)) + uint64(math.Pow(2.0, 30.0)) + 1}
-	// intializing bitset to binary representation of 2^63 + 2^30 + 1, so bits 0, 30, and 63 should be set
-	bs := &BitSet{size: 64, words: words}
+func TestBitSet_OrWords(t *testing.T) {
+	bs := NewBitSetWithInitialSize(64)
+	bs.SetBits([]int{1, 130})
+	bs.OrWords([]uint64{0, 0, 0xF << 60})
 
-	bitsToClear := []int{0, 15, 30, 45, 63}
-	bs.ClearBits(bitsToClear)
-	bools, numSet := bs.TestBits(bitsToClear)
+	if want := []int{1, 130, 188, 189, 190, 191}; !slices.Equal(bs.ToIndices(), want) {
+		t.Errorf("BitSet.OrWords() == %v, want %v", bs.ToIndices(), want)
+	}
+	if bs.Size() != 192 {
+		t.Errorf("BitSet.OrWords(): Size() == %d, want 192", bs.Size())
+	}
 
-	if numSet > 0 {
-		t.Errorf("BitSet.TestBits() want %d, got %d", 0, numSet)
+	bs.OrWords([]uint64{1, 0, 0, 0, 0, 0})
+	if bs.Size() != 384 || !bs.Test(0) {
+		t.Errorf("BitSet.OrWords() with trailing zero words: Size() == %d, Test(0) == %v, want 384, true", bs.Size(), bs.Test(0))
 	}
-	if !slices.Equal(bools, []bool{false, false, false, false, false}) {
-		t.Errorf("BitSet.TestBits() in ClearBits: want [true, false, true, false, true], got %v", bools)
+	bs.OrWords([]uint64{0, 1 << 3})
+	bs.OrWords(nil)
+	if bs.Size() != 384 || bs.CountSetBits() != 8 || !bs.Test(67) {
+		t.Errorf("BitSet.OrWords() with fewer words == %v (size %d), want 67 added (size 384)", bs.ToIndices(), bs.Size())
 	}
 }
 
-func TestBitSet_Flip(t *testing.T) {
-	words := []uint64{uint64(math.Pow(2.0, 63.0)) + 1}
-	// intializing bitset to binary representation of 2^63 + 1, so bits 0 and 63 should be set
-	bs := &BitSet{size: 64, words: words}
-
-	bs.Flip(64)
-
-	bs.Flip(-1)
+func TestBitSet_WordAt(t *testing.T) {
+	bs := NewBitSetWithInitialSize(500)
+	for i := 0; i < 100; i++ {
+		bs.Set(rand.Intn(500))
+	}
 
-	bs.Flip(0)
-	isSet := bs.Test(0)
-	if isSet {
-		t.Errorf("BitSet.Flip(0) on 1 bit should be 0, still have 1")
+	if bs.Len() != len(bs.words) {
+		t.Errorf("BitSet.Len() == %d, want %d", bs.Len(), len(bs.words))
+	}
+	count := 0
+	for i := 0; i < bs.Len(); i++ {
+		word, ok := bs.WordAt(i)
+		if !ok {
+			t.Fatalf("BitSet.WordAt(%d) returned ok == false, want true", i)
+		}
+		count += bits.OnesCount64(word)
+	}
+	if want := bs.CountSetBits(); count != want {
+		t.Errorf("sum of popcounts via BitSet.WordAt() == %d, want %d", count, want)
 	}
 
-	bs.Flip(0)
-	isSet = bs.Test(0)
-	if !isSet {
-		t.Errorf("BitSet.Flip(0) on 0 bit should be 1, still have 0")
+	for _, i := range []int{-1, bs.Len()} {
+		if word, ok := bs.WordAt(i); ok || word != 0 {
+			t.Errorf("BitSet.WordAt(%d) == %d, %v, want 0, false", i, word, ok)
+		}
 	}
+}
 
-	bs.Flip(63)
-	isSet = bs.Test(63)
-	if isSet {
-		t.Errorf("BitSet.Flip(0) on 1 bit should be 0, still have 1")
+func TestBitSet_SetWord(t *testing.T) {
+	bs := NewBitSetWithInitialSize(64)
+	bs.SetWord(0, 0b101)
+
+	if !slices.Equal(bs.ToIndices(), []int{0, 2}) {
+		t.Errorf("BitSet.SetWord(0, 0b101) == %v, want [0 2]", bs.ToIndices())
+	}
+	if word := bs.Word(0); word != 0b101 {
+		t.Errorf("BitSet.Word(0) == %b, want 101", word)
 	}
 
-	bs.Flip(63)
-	isSet = bs.Test(63)
-	if !isSet {
-		t.Errorf("BitSet.Flip(0) on 0 bit should be 1, still have 0")
+	bs.SetWord(2, 1<<4)
+	if !bs.Test(132) || bs.Size() != 133 {
+		t.Errorf("BitSet.SetWord(2, 1<<4): Test(132) == %v, Size() == %d, want true, 133", bs.Test(132), bs.Size())
+	}
+	if word := bs.Word(10); word != 0 {
+		t.Errorf("BitSet.Word(10) == %b, want 0", word)
 	}
+	if word := bs.Word(-1); word != 0 {
+		t.Errorf("BitSet.Word(-1) == %b, want 0", word)
+	}
+}
 
-	bs.Flip(30)
-	isSet = bs.Test(30)
-	if !isSet {
-		t.Errorf("BitSet.Test(0) on 0 should be 1, still have 0")
+func TestBitSet_ApplyWordMask(t *testing.T) {
+	bs := NewBitSetWithInitialSize(128)
+	bs.SetBits([]int{0, 3, 7, 8, 63, 64})
+
+	bs.ApplyWordMask(0, 0xff)
+	if want := []int{0, 3, 7, 64}; !slices.Equal(bs.ToIndices(), want) {
+		t.Errorf("BitSet.ApplyWordMask(0, 0xff) == %v, want %v", bs.ToIndices(), want)
+	}
+	bs.ApplyWordMask(5, 0)
+	bs.ApplyWordMask(-1, 0)
+	if want := []int{0, 3, 7, 64}; !slices.Equal(bs.ToIndices(), want) {
+		t.Errorf("BitSet.ApplyWordMask() out of range changed the bitset to %v", bs.ToIndices())
 	}
 }
 
-func TestBitSet_FlipBits(t *testing.T) {
-	words := []uint64{uint64(math.Pow(2.0, 63.0)) + uint64(math.Pow(2.0, 30.0)) + 1}
-	// intializing bitset to binary representation of 2^63 + 2^30 + 1, so bits 0, 30, and 63 should be set
-	bs := &BitSet{size: 64, words: words}
+func TestBitSet_SizeInBytes(t *testing.T) {
+	bs := NewBitSetWithInitialSize(512)
+	if size := bs.SizeInBytes(); size < 64 {
+		t.Errorf("BitSet.SizeInBytes() on size 512 == %d, want >= 64", size)
+	}
+	before := bs.SizeInBytes()
+	bs.Grow(1024)
+	if size := bs.SizeInBytes(); size <= before {
+		t.Errorf("BitSet.SizeInBytes() after Grow == %d, want > %d", size, before)
+	}
+}
 
-	bitsToFlip := []int{0, 15, 30, 45, 63}
-	bs.FlipBits(bitsToFlip)
-	bools, numSet := bs.TestBits(bitsToFlip)
+func TestBitSet_Cap(t *testing.T) {
+	bs := NewBitSetWithInitialSize(100)
+	if bs.Cap() < bs.Size() || bs.Cap()%64 != 0 {
+		t.Errorf("BitSet.Cap() == %d after construction, want multiple of 64 >= Size() == %d", bs.Cap(), bs.Size())
+	}
 
-	if numSet != 2 {
-		t.Errorf("BitSet.TestBits() want %d, got %d", 2, numSet)
+	bs.Grow(1000)
+	if bs.Cap() != len(bs.words)*64 || bs.Cap() < 1000 {
+		t.Errorf("BitSet.Cap() == %d after Grow(1000), want %d", bs.Cap(), len(bs.words)*64)
 	}
-	if !slices.Equal(bools, []bool{false, true, false, true, false}) {
-		t.Errorf("BitSet.TestBits() in ClearBits: want [true, false, true, false, true], got %v", bools)
+	if bs.Size() != 1000 {
+		t.Errorf("BitSet.Size() == %d after Grow(1000), want 1000", bs.Size())
 	}
 }
 
-func TestBitSet_Or_EqualLength(t *testing.T) {
-	a := NewBitSetWithInitialSize(10)
-	b := NewBitSetWithInitialSize(90)
+func TestBitSet_Grow(t *testing.T) {
+	bs := NewBitSetWithInitialSize(64)
+	bs.SetBits([]int{0, 30, 63})
+	bs.Grow(1000)
 
-	aToSet := []int{1}
-	bToSet := []int{0, 2, 4, 6, 45}
-	a.SetBits(aToSet)
-	b.SetBits(bToSet)
-	a.Or(b)
-	fmt.Println(a)
+	if len(bs.words) < 16 {
+		t.Errorf("BitSet.Grow(1000): len(words) == %d, want >= 16", len(bs.words))
+	}
+	if bs.Size() != 1000 {
+		t.Errorf("BitSet.Grow(1000): Size() == %d, want 1000", bs.Size())
+	}
+	if indices := bs.ToIndices(); !slices.Equal(indices, []int{0, 30, 63}) {
+		t.Errorf("BitSet.Grow(1000): got %v, want [0 30 63]", indices)
+	}
+
+	bs.Grow(10)
+	if bs.Size() != 1000 {
+		t.Errorf("BitSet.Grow(10) on larger bitset: Size() == %d, want 1000", bs.Size())
+	}
 }
 
-func TestBitSet_Or_SmallerReceiver(t *testing.T) {
-	a := NewBitSetWithInitialSize(80)
-	b := NewBitSetWithInitialSize(200)
+func TestBitSet_DrainSetBits(t *testing.T) {
+	bs := NewBitSetWithInitialSize(300)
+	for i := 0; i < 100; i++ {
+		bs.Set(rand.Intn(300))
+	}
+	want := bs.ToIndices()
 
-	aToSet := []int{1}
-	bToSet := []int{0, 2, 4, 6, 64}
-	a.SetBits(aToSet)
-	b.SetBits(bToSet)
-	str := b.String()
-	fmt.Println("b:", str, len(str), b.words)
-	//fmt.Println("a:", a, len(a.String()), "b:", b, len(b.String()), b.words)
-	a.Or(b)
-	fmt.Println(a)
+	if indices := bs.DrainSetBits(); !slices.Equal(indices, want) {
+		t.Errorf("BitSet.DrainSetBits() == %v, want %v", indices, want)
+	}
+	if !bs.None() || bs.Size() != 300 {
+		t.Errorf("BitSet.DrainSetBits(): None() == %v, Size() == %d, want true, 300", bs.None(), bs.Size())
+	}
+	if indices := bs.DrainSetBits(); len(indices) != 0 {
+		t.Errorf("BitSet.DrainSetBits() on empty bitset == %v, want []", indices)
+	}
 }
 
-func TestBitSet_Or_LargerReceiver(t *testing.T) {
-	a := NewBitSetWithInitialSize(10)
-	b := NewBitSetWithInitialSize(20)
+func TestBitSet_NextSetBit(t *testing.T) {
+	bs := NewBitSetWithInitialSize(200)
+	bs.SetBits([]int{3, 64, 199})
 
-	aToSet := []int{1}
-	bToSet := []int{0, 2, 4, 6, 18}
-	a.SetBits(aToSet)
-	b.SetBits(bToSet)
-	fmt.Println("a:", a, "b:", b)
-	a.Or(b)
-	fmt.Println(a)
+	for _, tt := range [][2]int{{-5, 3}, {0, 3}, {3, 3}, {4, 64}, {65, 199}, {199, 199}, {200, -1}} {
+		if n := bs.NextSetBit(tt[0]); n != tt[1] {
+			t.Errorf("BitSet.NextSetBit(%d) == %d, want %d", tt[0], n, tt[1])
+		}
+	}
 }
 
-func TestBitSet_And_LargerReceiver(t *testing.T) {
-	a := NewBitSetWithInitialSize(80)
-	b := NewBitSetWithInitialSize(156)
+func TestBitSet_NextSetBits(t *testing.T) {
+	bs := NewBitSetWithInitialSize(1000)
+	for i := 0; i < 150; i++ {
+		bs.Set(rand.Intn(1000))
+	}
+	want := bs.ToIndices()
 
-	aToSet := []int{1, 5, 10, 15, 17, 29}
-	bToSet := []int{1, 29, 150}
-	a.SetBits(aToSet)
-	b.SetBits(bToSet)
-	str := b.String()
-	fmt.Println("b:", str, len(str), b.words)
-	a.And(b)
-	//res := And(a, b)
-	fmt.Println(a)
+	for _, limit := range []int{1, 7, 64, 1000} {
+		var got []int
+		for from := 0; ; {
+			chunk := bs.NextSetBits(from, limit)
+			got = append(got, chunk...)
+			if len(chunk) < limit {
+				break
+			}
+			from = chunk[len(chunk)-1] + 1
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("chunked BitSet.NextSetBits() with limit %d == %v, want %v", limit, got, want)
+		}
+	}
+
+	bs = NewBitSetWithInitialSize(200)
+	bs.SetBits([]int{3, 64, 65, 199})
+	if got := bs.NextSetBits(4, 2); !slices.Equal(got, []int{64, 65}) {
+		t.Errorf("BitSet.NextSetBits(4, 2) == %v, want [64 65]", got)
+	}
+	if got := bs.NextSetBits(-5, 10); !slices.Equal(got, []int{3, 64, 65, 199}) {
+		t.Errorf("BitSet.NextSetBits(-5, 10) == %v, want [3 64 65 199]", got)
+	}
+	if got := bs.NextSetBits(200, 10); len(got) != 0 {
+		t.Errorf("BitSet.NextSetBits(200, 10) == %v, want []", got)
+	}
+	if got := bs.NextSetBits(0, 0); len(got) != 0 {
+		t.Errorf("BitSet.NextSetBits(0, 0) == %v, want []", got)
+	}
 
+	bs = NewBitSetWithInitialSize(1 << 20)
+	bs.Set(1000)
+	if got := bs.NextSetBits(0, 1<<30); !slices.Equal(got, []int{1000}) || cap(got) > 64 {
+		t.Errorf("BitSet.NextSetBits(0, 1<<30) == %v with capacity %d, want [1000] with capacity <= 64", got, cap(got))
+	}
 }
 
-func TestBitSet_And_Smaller(t *testing.T) {
-	a := NewBitSetWithInitialSize(20)
-	b := NewBitSetWithInitialSize(64)
+func TestBitSet_NextClearBit(t *testing.T) {
+	bs := NewBitSetWithInitialSize(130)
+	bs.SetRange(0, 70)
+	bs.Set(71)
 
-	aToSet := []int{1, 5, 10, 15, 17}
-	bToSet := []int{1, 5, 10, 15, 17}
-	a.SetBits(aToSet)
-	b.SetBits(bToSet)
-	fmt.Println("b arr:", b.words)
-	fmt.Println("a:", a, "b: ", b)
-	a.And(b)
-	//res := And(a, b)
-	fmt.Printf("%b\n", 0b00101000010000100010&0b1001001001001)
-	fmt.Println(a)
+	for _, tt := range [][2]int{{0, 70}, {70, 70}, {71, 72}, {129, 129}, {130, -1}} {
+		if n := bs.NextClearBit(tt[0]); n != tt[1] {
+			t.Errorf("BitSet.NextClearBit(%d) == %d, want %d", tt[0], n, tt[1])
+		}
+	}
+	bs.SetAll()
+	if n := bs.NextClearBit(0); n != -1 {
+		t.Errorf("BitSet.NextClearBit(0) on full bitset == %d, want -1", n)
+	}
 }
 
-func TestBitSet_Xor_Smaller(t *testing.T) {
-	a := NewBitSetWithInitialSize(20)
-	b := NewBitSetWithInitialSize(64)
+func TestBitSet_Runs(t *testing.T) {
+	bs := NewBitSetWithInitialSize(200)
+	bs.SetRange(0, 5)
+	bs.SetRange(60, 130)
+	bs.SetRange(190, 200)
 
-	a.Not()
-	b.Not()
-	aToClear := []int{1, 5, 10, 15, 17}
-	a.ClearBits(aToClear)
-	fmt.Println(a, b)
-	a.Xor(b)
-	fmt.Println(a)
+	if runs, want := bs.Runs(), [][2]int{{0, 5}, {60, 130}, {190, 200}}; !slices.Equal(runs, want) {
+		t.Errorf("BitSet.Runs() == %v, want %v", runs, want)
+	}
+	if runs := NewBitSet().Runs(); len(runs) != 0 {
+		t.Errorf("BitSet.Runs() on empty bitset == %v, want []", runs)
+	}
 }
 
-func TestBitSet_Not(t *testing.T) {
-	a := NewBitSetWithInitialSize(20)
+func TestBitSet_Gaps(t *testing.T) {
+	bs := NewBitSetWithInitialSize(100)
+	bs.SetBits([]int{2, 3, 10, 70})
+
+	if gaps, want := bs.Gaps(), []int{2, 0, 6, 59, 29}; !slices.Equal(gaps, want) {
+		t.Errorf("BitSet.Gaps() == %v, want %v", gaps, want)
+	}
+	bs.SetBits([]int{0, 99})
+	if gaps, want := bs.Gaps(), []int{0, 1, 0, 6, 59, 28, 0}; !slices.Equal(gaps, want) {
+		t.Errorf("BitSet.Gaps() == %v, want %v", gaps, want)
+	}
+	if gaps := NewBitSetWithInitialSize(50).Gaps(); !slices.Equal(gaps, []int{50}) {
+		t.Errorf("BitSet.Gaps() on empty bitset == %v, want [50]", gaps)
+	}
+}
+
+func TestBitSet_PopFirstLast(t *testing.T) {
+	bs := NewBitSetWithInitialSize(300)
+	for i := 0; i < 50; i++ {
+		bs.Set(rand.Intn(300))
+	}
+	want := bs.ToIndices()
+
+	indices := []int{}
+	for idx, ok := bs.PopFirst(); ok; idx, ok = bs.PopFirst() {
+		indices = append(indices, idx)
+	}
+	if !slices.Equal(indices, want) {
+		t.Errorf("BitSet.PopFirst() yielded %v, want %v", indices, want)
+	}
+	if _, ok := bs.PopFirst(); ok || bs.Any() {
+		t.Errorf("BitSet.PopFirst() on empty bitset returned ok == true")
+	}
+
+	bs.SetBits([]int{3, 70, 299})
+	for _, want := range []int{299, 70, 3} {
+		if idx, ok := bs.PopLast(); !ok || idx != want {
+			t.Errorf("BitSet.PopLast() == %d, %v, want %d, true", idx, ok, want)
+		}
+	}
+	if _, ok := bs.PopLast(); ok {
+		t.Errorf("BitSet.PopLast() on empty bitset returned ok == true")
+	}
+}
+
+func TestBitSet_Resize(t *testing.T) {
+	bs := NewBitSetWithInitialSize(64)
+	bs.SetBits([]int{1, 63})
+
+	bs.Resize(300)
+	if bs.Size() != 300 || len(bs.words) < 5 || !slices.Equal(bs.ToIndices(), []int{1, 63}) {
+		t.Errorf("BitSet.Resize(300) == %v (size %d, %d words), want [1 63] (size 300, >= 5 words)", bs.ToIndices(), bs.Size(), len(bs.words))
+	}
+
+	bs.SetBits([]int{100, 299})
+	bs.Resize(70)
+	if bs.Size() != 70 || len(bs.words) != 2 || !slices.Equal(bs.ToIndices(), []int{1, 63}) {
+		t.Errorf("BitSet.Resize(70) == %v (size %d, %d words), want [1 63] (size 70, 2 words)", bs.ToIndices(), bs.Size(), len(bs.words))
+	}
+
+	// bits cleared by shrinking must not come back when growing again
+	bs.SetBits([]int{65, 69})
+	bs.Resize(66)
+	bs.Resize(300)
+	if want := []int{1, 63, 65}; !slices.Equal(bs.ToIndices(), want) {
+		t.Errorf("BitSet.Resize(66) then Resize(300) == %v, want %v", bs.ToIndices(), want)
+	}
+}
+
+func TestBitSet_MinMax(t *testing.T) {
+	bs := NewBitSetWithInitialSize(200)
+	if _, ok := bs.Min(); ok {
+		t.Errorf("BitSet.Min() on empty bitset: ok == true, want false")
+	}
+	if _, ok := bs.Max(); ok {
+		t.Errorf("BitSet.Max() on empty bitset: ok == true, want false")
+	}
+
+	bs.SetBits([]int{70, 5, 199, 64})
+	if lo, ok := bs.Min(); lo != 5 || !ok {
+		t.Errorf("BitSet.Min() == %d, %v, want 5, true", lo, ok)
+	}
+	if hi, ok := bs.Max(); hi != 199 || !ok {
+		t.Errorf("BitSet.Max() == %d, %v, want 199, true", hi, ok)
+	}
+
+	bs.Clear(5)
+	bs.Clear(199)
+	if lo, hi := bs.NextSetBit(0), bs.LastSetBit(); lo != 64 || hi != 70 {
+		t.Errorf("after clearing 5 and 199: NextSetBit(0), LastSetBit() == %d, %d, want 64, 70", lo, hi)
+	}
+	if lo, _ := bs.Min(); lo != 64 {
+		t.Errorf("BitSet.Min() == %d, want 64", lo)
+	}
+	if hi, _ := bs.Max(); hi != 70 {
+		t.Errorf("BitSet.Max() == %d, want 70", hi)
+	}
+}
+
+func TestBitSet_LastSetBit(t *testing.T) {
+	bs := NewBitSetWithInitialSize(200)
+	if last := bs.LastSetBit(); last != -1 {
+		t.Errorf("BitSet.LastSetBit() on empty bitset == %d, want -1", last)
+	}
+	bs.SetBits([]int{3, 64, 130})
+	if last := bs.LastSetBit(); last != 130 {
+		t.Errorf("BitSet.LastSetBit() == %d, want 130", last)
+	}
+}
+
+func TestBitSet_TrailingLeadingZeros(t *testing.T) {
+	bs := NewBitSetWithInitialSize(150)
+	if tz, lz := bs.TrailingZeros(), bs.LeadingZeros(); tz != 150 || lz != 150 {
+		t.Errorf("BitSet.TrailingZeros(), LeadingZeros() on empty bitset == %d, %d, want 150, 150", tz, lz)
+	}
+
+	bs.Set(2)
+	if tz, lz := bs.TrailingZeros(), bs.LeadingZeros(); tz != 2 || lz != 147 {
+		t.Errorf("BitSet.TrailingZeros(), LeadingZeros() with bit 2 == %d, %d, want 2, 147", tz, lz)
+	}
+
+	bs.Clear(2)
+	bs.Set(130)
+	if tz, lz := bs.TrailingZeros(), bs.LeadingZeros(); tz != 130 || lz != 19 {
+		t.Errorf("BitSet.TrailingZeros(), LeadingZeros() with bit 130 == %d, %d, want 130, 19", tz, lz)
+	}
+}
+
+func TestBitSet_Compact(t *testing.T) {
+	bs := NewBitSetWithInitialSize(64)
+	bs.SetBits([]int{1, 40})
+	bs.Set(1000)
+	numWords := len(bs.words)
+	bs.Clear(1000)
+	bs.Compact()
+
+	if len(bs.words) >= numWords || len(bs.words) != 1 || cap(bs.words) != 1 {
+		t.Errorf("BitSet.Compact(): len(words) == %d, cap(words) == %d, want 1, 1", len(bs.words), cap(bs.words))
+	}
+	if bs.Size() != 41 {
+		t.Errorf("BitSet.Compact(): Size() == %d, want 41", bs.Size())
+	}
+	if indices := bs.ToIndices(); !slices.Equal(indices, []int{1, 40}) {
+		t.Errorf("BitSet.Compact(): got %v, want [1 40]", indices)
+	}
+
+	bs.ClearAll()
+	bs.Compact()
+	if len(bs.words) != 1 || bs.Size() != 0 {
+		t.Errorf("BitSet.Compact() on empty bitset: len(words) == %d, Size() == %d, want 1, 0", len(bs.words), bs.Size())
+	}
+}
+
+func TestBitSet_Trim(t *testing.T) {
+	for _, indices := range [][]int{{0}, {5, 63}, {1, 64}, {3, 200, 700}} {
+		bs := NewBitSetWithInitialSize(1000)
+		bs.SetBits(indices)
+		bs.Trim()
+
+		last := indices[len(indices)-1]
+		if bs.Size() != last+1 || len(bs.words) != last/64+1 || cap(bs.words) != last/64+1 {
+			t.Errorf("BitSet.Trim() with bits %v: Size() == %d, len(words) == %d, cap(words) == %d, want %d, %d, %d", indices, bs.Size(), len(bs.words), cap(bs.words), last+1, last/64+1, last/64+1)
+		}
+		if got := bs.ToIndices(); !slices.Equal(got, indices) {
+			t.Errorf("BitSet.Trim() with bits %v: got %v", indices, got)
+		}
+	}
+
+	bs := NewBitSetWithInitialSize(300)
+	bs.Trim()
+	if bs.Size() != 0 || bs.Any() {
+		t.Errorf("BitSet.Trim() on empty bitset: Size() == %d, want 0", bs.Size())
+	}
+}
+
+func TestBitSet_SetBool(t *testing.T) {
+	values := []bool{true, false, true, true, false, false, true}
+	bs := NewBitSetWithInitialSize(4)
+	bs.SetAll()
+	indices := make([]int, len(values))
+	for i, v := range values {
+		bs.SetBool(i, v)
+		indices[i] = i
+	}
+
+	if bools, _ := bs.TestBits(indices); !slices.Equal(bools, values) {
+		t.Errorf("BitSet.SetBool(): TestBits() == %v, want %v", bools, values)
+	}
+}
+
+func TestBitSet_SetRange(t *testing.T) {
+	bs := NewBitSetWithInitialSize(64)
+	bs.SetRange(60, 130)
+
+	if bs.Size() != 130 || bs.CountSetBits() != 70 {
+		t.Errorf("BitSet.SetRange(60, 130): Size() == %d, CountSetBits() == %d, want 130, 70", bs.Size(), bs.CountSetBits())
+	}
+	if bs.Test(59) || !bs.Test(60) || !bs.Test(129) {
+		t.Errorf("BitSet.SetRange(60, 130): wrong bits at range boundaries")
+	}
+
+	bs.ClearRange(62, 128)
+	if want := []int{60, 61, 128, 129}; !slices.Equal(bs.ToIndices(), want) {
+		t.Errorf("BitSet.ClearRange(62, 128) == %v, want %v", bs.ToIndices(), want)
+	}
+	bs.ClearRange(0, 1000)
+	if bs.Any() || bs.Size() != 130 {
+		t.Errorf("BitSet.ClearRange(0, 1000): Any() == %v, Size() == %d, want false, 130", bs.Any(), bs.Size())
+	}
+}
+
+func TestBitSet_SetRangeValue(t *testing.T) {
+	bs := NewBitSetWithInitialSize(200)
+	bs.SetBits([]int{9, 150})
+
+	bs.SetRangeValue(10, 140, true)
+	if bs.CountSetBits() != 132 || !bs.Test(10) || !bs.Test(139) || bs.Test(140) {
+		t.Errorf("BitSet.SetRangeValue(10, 140, true): CountSetBits() == %d, want 132", bs.CountSetBits())
+	}
+	bs.SetRangeValue(10, 140, false)
+	if want := []int{9, 150}; !slices.Equal(bs.ToIndices(), want) {
+		t.Errorf("BitSet.SetRangeValue(10, 140, false) == %v, want %v", bs.ToIndices(), want)
+	}
+}
+
+func TestBitSet_SetBits(t *testing.T) {
+	bs := NewBitSetWithInitialSize(64)
+	bitsToSet := []int{0, 63, 0, 5, 10}
+	bs.SetBits(bitsToSet)
+	bools, numSet := bs.TestBits(bitsToSet)
+
+	if numSet != len(bitsToSet) {
+		t.Errorf("BitSet.TestBits() want %d, got %d", len(bitsToSet), numSet)
+	}
+	if !slices.Equal(bools, []bool{true, true, true, true, true}) {
+		t.Errorf("BitSet.TestBits() want [true, false, true, false, true], got %v", bools)
+	}
+}
+
+func TestBitSet_SetBitsChecked(t *testing.T) {
+	bs := NewBitSetWithInitialSize(64)
+	invalid := bs.SetBitsChecked([]int{3, -1, 200, -64, 0, 3})
+
+	if want := []int{-1, -64}; !slices.Equal(invalid, want) {
+		t.Errorf("BitSet.SetBitsChecked() returned %v, want %v", invalid, want)
+	}
+	if want := []int{0, 3, 200}; !slices.Equal(bs.ToIndices(), want) {
+		t.Errorf("BitSet.SetBitsChecked() set %v, want %v", bs.ToIndices(), want)
+	}
+	if invalid := bs.SetBitsChecked([]int{1, 2}); invalid != nil {
+		t.Errorf("BitSet.SetBitsChecked() with valid indices returned %v, want nil", invalid)
+	}
+}
+
+func TestBitSet_SetBitsSorted(t *testing.T) {
+	indices := []int{-3, -1}
+	for i := 0; i < 500; i++ {
+		indices = append(indices, rand.Intn(5000))
+	}
+	slices.Sort(indices)
+
+	want := NewBitSetWithInitialSize(64)
+	want.SetBits(indices)
+	bs := NewBitSetWithInitialSize(64)
+	bs.SetBitsSorted(indices)
+	if bs.Size() != want.Size() || !slices.Equal(bs.ToIndices(), want.ToIndices()) {
+		t.Errorf("BitSet.SetBitsSorted() (size %d) doesn't match SetBits() (size %d)", bs.Size(), want.Size())
+	}
+
+	bs.SetBitsSorted(nil)
+	bs.SetBitsSorted([]int{-2})
+	if !slices.Equal(bs.ToIndices(), want.ToIndices()) {
+		t.Errorf("BitSet.SetBitsSorted() with no valid indices changed the bitset")
+	}
+
+	bs = NewBitSetWithInitialSize(64)
+	bs.SetBitsSorted([]int{500, 3, -1, 70, 4})
+	if bs.Size() != 501 || !slices.Equal(bs.ToIndices(), []int{3, 4, 70, 500}) {
+		t.Errorf("BitSet.SetBitsSorted() with unsorted indices == %v (size %d), want [3 4 70 500] (size 501)", bs.ToIndices(), bs.Size())
+	}
+}
+
+func benchmarkIndices() []int {
+	indices := make([]int, 10000)
+	for i := range indices {
+		indices[i] = i * 7
+	}
+	return indices
+}
+
+func BenchmarkBitSet_SetBits(b *testing.B) {
+	indices := benchmarkIndices()
+	for i := 0; i < b.N; i++ {
+		NewBitSet().SetBits(indices)
+	}
+}
+
+func BenchmarkBitSet_SetBitsSorted(b *testing.B) {
+	indices := benchmarkIndices()
+	for i := 0; i < b.N; i++ {
+		NewBitSet().SetBitsSorted(indices)
+	}
+}
+
+func TestBitSet_SetBitsAtomic(t *testing.T) {
+	bs := NewBitSetWithInitialSize(64)
+	bitsToSet := []int{0, 5, 63}
+	if err := bs.SetBitsAtomic(bitsToSet); err != nil {
+		t.Errorf("BitSet.SetBitsAtomic(%v) returned error: %v", bitsToSet, err)
+	}
+	if _, numSet := bs.TestBits(bitsToSet); numSet != len(bitsToSet) {
+		t.Errorf("BitSet.SetBitsAtomic(%v): got %d set bits, want %d", bitsToSet, numSet, len(bitsToSet))
+	}
+
+	bs = NewBitSetWithInitialSize(64)
+	for _, bitsToSet := range [][]int{{1, 2, 64}, {1, -1, 2}} {
+		if err := bs.SetBitsAtomic(bitsToSet); err == nil {
+			t.Errorf("BitSet.SetBitsAtomic(%v) returned nil error", bitsToSet)
+		}
+		if count := bs.CountSetBits(); count != 0 {
+			t.Errorf("BitSet.SetBitsAtomic(%v) with invalid index: got %d set bits, want 0", bitsToSet, count)
+		}
+	}
+	if bs.Size() != 64 {
+		t.Errorf("BitSet.SetBitsAtomic() with invalid index: Size() == %d, want 64", bs.Size())
+	}
+}
+
+func TestBitSet_Clear(t *testing.T) {
+	words := []uint64{uint64(math.Pow(2.0, 63.0)) + 1}
+	// intializing bitset to binary representation of 2^63 + 1, so bits 0 and 63 should be set
+	bs := &BitSet{size: 64, words: words}
+
+	bs.Clear(64)
+
+	bs.Clear(-1)
+
+	bs.Clear(0)
+	isSet := bs.Test(0)
+	if isSet {
+		t.Errorf("BitSet.Test(0) == true, want false")
+	}
+
+	bs.Clear(63)
+	isSet = bs.Test(63)
+	if isSet {
+		t.Errorf("BitSet.Test(63) == true, want false")
+	}
+
+	bs.Clear(30)
+	isSet = bs.Test(30)
+	if isSet {
+		t.Errorf("BitSet.Test(0) on zero bit == true, want false")
+	}
+
+	bs.Clear(0)
+	isSet = bs.Test(0)
+	if isSet {
+		t.Errorf("BitSet.Test(0) on cleared bit == true, want false")
+	}
+}
+
+func TestBitSet_ClearBitsSorted(t *testing.T) {
+	indices := []int{-1}
+	for i := 0; i < 500; i++ {
+		indices = append(indices, rand.Intn(6000))
+	}
+	slices.Sort(indices)
+
+	want := NewBitSetWithInitialSize(5000)
+	want.SetAll()
+	bs := want.Clone()
+	want.ClearBits(indices)
+	bs.ClearBitsSorted(indices)
+	if bs.Size() != want.Size() || !slices.Equal(bs.ToIndices(), want.ToIndices()) {
+		t.Errorf("BitSet.ClearBitsSorted() (size %d) doesn't match ClearBits() (size %d)", bs.Size(), want.Size())
+	}
+}
+
+func BenchmarkBitSet_ClearBits(b *testing.B) {
+	indices := benchmarkIndices()
+	bs := NewBitSetWithInitialSize(70000)
+	for i := 0; i < b.N; i++ {
+		bs.ClearBits(indices)
+	}
+}
+
+func BenchmarkBitSet_ClearBitsSorted(b *testing.B) {
+	indices := benchmarkIndices()
+	bs := NewBitSetWithInitialSize(70000)
+	for i := 0; i < b.N; i++ {
+		bs.ClearBitsSorted(indices)
+	}
+}
+
+func TestBitSet_Clear_DoesNotGrow(t *testing.T) {
+	bs := NewBitSetWithInitialSize(64)
+	bs.Set(3)
+	numWords := len(bs.words)
+	bs.Clear(1000)
+	bs.ClearBits([]int{3, 2000})
+
+	if len(bs.words) != numWords || bs.Size() != 64 {
+		t.Errorf("BitSet.Clear(1000): len(words) == %d, Size() == %d, want %d, 64", len(bs.words), bs.Size(), numWords)
+	}
+	if bs.Test(3) {
+		t.Errorf("BitSet.ClearBits() didn't clear bit 3")
+	}
+}
+
+func TestBitSet_ClearBits(t *testing.T) {
+	words := []uint64{uint64(math.Pow(2.0, 63.0)) + uint64(math.Pow(2.0, 30.0)) + 1}
+	// intializing bitset to binary representation of 2^63 + 2^30 + 1, so bits 0, 30, and 63 should be set
+	bs := &BitSet{size: 64, words: words}
+
+	bitsToClear := []int{0, 15, 30, 45, 63}
+	bs.ClearBits(bitsToClear)
+	bools, numSet := bs.TestBits(bitsToClear)
+
+	if numSet > 0 {
+		t.Errorf("BitSet.TestBits() want %d, got %d", 0, numSet)
+	}
+	if !slices.Equal(bools, []bool{false, false, false, false, false}) {
+		t.Errorf("BitSet.TestBits() in ClearBits: want [true, false, true, false, true], got %v", bools)
+	}
+}
+
+func TestBitSet_ClearAll(t *testing.T) {
+	bs := NewBitSetWithInitialSize(300)
+	bs.SetBits([]int{0, 64, 299})
+	words := bs.words
+	bs.ClearAll()
+
+	if bs.Any() || bs.Size() != 300 {
+		t.Errorf("BitSet.ClearAll(): Any() == %v, Size() == %d, want false, 300", bs.Any(), bs.Size())
+	}
+	if &words[0] != &bs.words[0] {
+		t.Errorf("BitSet.ClearAll() reallocated the words")
+	}
+
+	bs = &BitSet{size: 300, words: make([]uint64, 20)}
+	bs.SetBits([]int{0, 64, 299})
+	bs.Free()
+	if bs.Any() || bs.Size() != 300 || len(bs.words) != 5 {
+		t.Errorf("BitSet.Free(): Any() == %v, Size() == %d, len(words) == %d, want false, 300, 5", bs.Any(), bs.Size(), len(bs.words))
+	}
+}
+
+func BenchmarkBitSet_Free(b *testing.B) {
+	bs := NewBitSetWithInitialSize(1 << 16)
+	for i := 0; i < b.N; i++ {
+		bs.Set(i % bs.Size())
+		bs.Free()
+	}
+}
+
+func TestBitSet_Reset(t *testing.T) {
+	bs := NewBitSetWithInitialSize(300)
+	bs.SetBits([]int{0, 64, 299})
+	words := bs.words
+	bs.Reset()
+
+	if bs.Any() || bs.Size() != 300 {
+		t.Errorf("BitSet.Reset(): Any() == %v, Size() == %d, want false, 300", bs.Any(), bs.Size())
+	}
+	if &words[0] != &bs.words[0] {
+		t.Errorf("BitSet.Reset() reallocated the words")
+	}
+}
+
+func BenchmarkBitSet_Reset(b *testing.B) {
+	bs := NewBitSetWithInitialSize(1 << 16)
+	for i := 0; i < b.N; i++ {
+		bs.Set(i % bs.Size())
+		bs.Reset()
+	}
+}
+
+func BenchmarkBitSet_ClearAll(b *testing.B) {
+	bs := NewBitSetWithInitialSize(1 << 16)
+	for i := 0; i < b.N; i++ {
+		bs.Set(i % bs.Size())
+		bs.ClearAll()
+	}
+}
+
+func TestBitSet_SetAll(t *testing.T) {
+	for _, numBits := range []int{128, 70} {
+		bs := NewBitSetWithInitialSize(numBits)
+		bs.SetAll()
+
+		if count := bs.CountSetBits(); count != numBits {
+			t.Errorf("BitSet.SetAll() on size %d: CountSetBits() == %d, want %d", numBits, count, numBits)
+		}
+		if !bs.Test(numBits - 1) {
+			t.Errorf("BitSet.SetAll() on size %d: Test(%d) == false, want true", numBits, numBits-1)
+		}
+	}
+}
+
+func TestBitSet_Flip(t *testing.T) {
+	words := []uint64{uint64(math.Pow(2.0, 63.0)) + 1}
+	// intializing bitset to binary representation of 2^63 + 1, so bits 0 and 63 should be set
+	bs := &BitSet{size: 64, words: words}
+
+	bs.Flip(64)
+
+	bs.Flip(-1)
+
+	bs.Flip(0)
+	isSet := bs.Test(0)
+	if isSet {
+		t.Errorf("BitSet.Flip(0) on 1 bit should be 0, still have 1")
+	}
+
+	bs.Flip(0)
+	isSet = bs.Test(0)
+	if !isSet {
+		t.Errorf("BitSet.Flip(0) on 0 bit should be 1, still have 0")
+	}
+
+	bs.Flip(63)
+	isSet = bs.Test(63)
+	if isSet {
+		t.Errorf("BitSet.Flip(0) on 1 bit should be 0, still have 1")
+	}
+
+	bs.Flip(63)
+	isSet = bs.Test(63)
+	if !isSet {
+		t.Errorf("BitSet.Flip(0) on 0 bit should be 1, still have 0")
+	}
+
+	bs.Flip(30)
+	isSet = bs.Test(30)
+	if !isSet {
+		t.Errorf("BitSet.Test(0) on 0 should be 1, still have 0")
+	}
+}
+
+func TestBitSet_FlipStrict(t *testing.T) {
+	bs := NewBitSetWithInitialSize(64)
+	numWords := len(bs.words)
+
+	if err := bs.FlipStrict(10); err != nil || !bs.Test(10) {
+		t.Errorf("BitSet.FlipStrict(10) == %v, Test(10) == %v, want nil, true", err, bs.Test(10))
+	}
+	for _, n := range []int{-1, 64, 1000} {
+		if err := bs.FlipStrict(n); err == nil {
+			t.Errorf("BitSet.FlipStrict(%d) returned nil error", n)
+		}
+	}
+	if len(bs.words) != numWords || bs.Size() != 64 {
+		t.Errorf("BitSet.FlipStrict() grew the bitset: len(words) == %d, Size() == %d", len(bs.words), bs.Size())
+	}
+	if allocs := testing.AllocsPerRun(10, func() { bs.FlipStrict(10) }); allocs != 0 {
+		t.Errorf("BitSet.FlipStrict(10) allocated %f times, want 0", allocs)
+	}
+}
+
+func TestBitSet_FlipBits(t *testing.T) {
+	words := []uint64{uint64(math.Pow(2.0, 63.0)) + uint64(math.Pow(2.0, 30.0)) + 1}
+	// intializing bitset to binary representation of 2^63 + 2^30 + 1, so bits 0, 30, and 63 should be set
+	bs := &BitSet{size: 64, words: words}
+
+	bitsToFlip := []int{0, 15, 30, 45, 63}
+	bs.FlipBits(bitsToFlip)
+	bools, numSet := bs.TestBits(bitsToFlip)
+
+	if numSet != 2 {
+		t.Errorf("BitSet.TestBits() want %d, got %d", 2, numSet)
+	}
+	if !slices.Equal(bools, []bool{false, true, false, true, false}) {
+		t.Errorf("BitSet.TestBits() in ClearBits: want [true, false, true, false, true], got %v", bools)
+	}
+}
+
+func TestBitSet_TestAndSet(t *testing.T) {
+	bs := NewBitSetWithInitialSize(64)
+
+	if prev := bs.TestAndSet(10); prev {
+		t.Errorf("BitSet.TestAndSet(10) on clear bit == true, want false")
+	}
+	if prev := bs.TestAndSet(10); !prev {
+		t.Errorf("BitSet.TestAndSet(10) on set bit == false, want true")
+	}
+	if !bs.Test(10) {
+		t.Errorf("BitSet.Test(10) == false after TestAndSet, want true")
+	}
+
+	if prev := bs.TestAndClear(10); !prev {
+		t.Errorf("BitSet.TestAndClear(10) on set bit == false, want true")
+	}
+	if prev := bs.TestAndClear(10); prev {
+		t.Errorf("BitSet.TestAndClear(10) on clear bit == true, want false")
+	}
+	if bs.Test(10) {
+		t.Errorf("BitSet.Test(10) == true after TestAndClear, want false")
+	}
+}
+
+func TestBitSet_AndReport(t *testing.T) {
+	bs := NewBitSetWithInitialSize(64)
+
+	if changed := bs.SetAndReport(3); !changed {
+		t.Errorf("BitSet.SetAndReport(3) on clear bit == false, want true")
+	}
+	if changed := bs.SetAndReport(3); changed {
+		t.Errorf("BitSet.SetAndReport(3) on set bit == true, want false")
+	}
+	if changed := bs.ClearAndReport(3); !changed {
+		t.Errorf("BitSet.ClearAndReport(3) on set bit == false, want true")
+	}
+	if changed := bs.ClearAndReport(3); changed {
+		t.Errorf("BitSet.ClearAndReport(3) on clear bit == true, want false")
+	}
+	if changed := bs.FlipAndReport(3); !changed || !bs.Test(3) {
+		t.Errorf("BitSet.FlipAndReport(3) == %v, Test(3) == %v, want true, true", changed, bs.Test(3))
+	}
+	if bs.SetAndReport(-1) || bs.ClearAndReport(-1) || bs.FlipAndReport(-1) {
+		t.Errorf("BitSet.*AndReport(-1) reported a change, want false")
+	}
+}
+
+func TestBitSet_SwapBits(t *testing.T) {
+	bs := NewBitSetWithInitialSize(100)
+	bs.SetBits([]int{3, 7})
+
+	bs.SwapBits(3, 90)
+	if want := []int{7, 90}; !slices.Equal(bs.ToIndices(), want) {
+		t.Errorf("BitSet.SwapBits(3, 90) == %v, want %v", bs.ToIndices(), want)
+	}
+	bs.SwapBits(3, 90)
+	if want := []int{3, 7}; !slices.Equal(bs.ToIndices(), want) {
+		t.Errorf("BitSet.SwapBits(3, 90) twice == %v, want %v", bs.ToIndices(), want)
+	}
+	bs.SwapBits(3, 7)
+	if want := []int{3, 7}; !slices.Equal(bs.ToIndices(), want) {
+		t.Errorf("BitSet.SwapBits(3, 7) on equal bits == %v, want %v", bs.ToIndices(), want)
+	}
+	bs.SwapBits(7, 200)
+	if want := []int{3, 200}; bs.Size() != 201 || !slices.Equal(bs.ToIndices(), want) {
+		t.Errorf("BitSet.SwapBits(7, 200) == %v (size %d), want %v (size 201)", bs.ToIndices(), bs.Size(), want)
+	}
+}
+
+func TestBitSet_ErrorVariants(t *testing.T) {
+	bs := NewBitSetWithInitialSize(64)
+	numWords := len(bs.words)
+
+	if err := bs.SetE(5); err != nil {
+		t.Errorf("BitSet.SetE(5) returned error: %v", err)
+	}
+	if isSet, err := bs.TestE(5); err != nil || !isSet {
+		t.Errorf("BitSet.TestE(5) == (%v, %v), want (true, nil)", isSet, err)
+	}
+	if err := bs.FlipE(6); err != nil || !bs.Test(6) {
+		t.Errorf("BitSet.FlipE(6) returned error %v or didn't flip bit", err)
+	}
+	if err := bs.ClearE(5); err != nil || bs.Test(5) {
+		t.Errorf("BitSet.ClearE(5) returned error %v or didn't clear bit", err)
+	}
+
+	for _, n := range []int{-1, 64, 1000} {
+		wantIdx, wantSize := fmt.Sprint(n), fmt.Sprint(bs.Size())
+		errs := []error{bs.SetE(n), bs.ClearE(n), bs.FlipE(n)}
+		_, err := bs.TestE(n)
+		errs = append(errs, err)
+		for _, err := range errs {
+			if err == nil {
+				t.Errorf("BitSet error variant with index %d returned nil error", n)
+				continue
+			}
+			if !strings.Contains(err.Error(), wantIdx) || !strings.Contains(err.Error(), wantSize) {
+				t.Errorf("error %q doesn't mention index %s and size %s", err, wantIdx, wantSize)
+			}
+		}
+	}
+	if bs.Size() != 64 || len(bs.words) != numWords {
+		t.Errorf("BitSet error variants grew the bitset: Size() == %d, len(words) == %d", bs.Size(), len(bs.words))
+	}
+}
+
+func TestBitSet_RandomSetBit(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	if _, ok := NewBitSet().RandomSetBit(r); ok {
+		t.Errorf("BitSet.RandomSetBit() on empty bitset returned ok == true")
+	}
+
+	bs := NewBitSetWithInitialSize(300)
+	bitsToSet := []int{0, 7, 64, 150, 299}
+	bs.SetBits(bitsToSet)
+	numDraws := 50000
+	counts := make(map[int]int)
+	for i := 0; i < numDraws; i++ {
+		idx, ok := bs.RandomSetBit(r)
+		if !ok || !bs.Test(idx) {
+			t.Fatalf("BitSet.RandomSetBit() == %d, %v, want a set bit", idx, ok)
+		}
+		counts[idx]++
+	}
+	want := float64(numDraws) / float64(len(bitsToSet))
+	for _, bit := range bitsToSet {
+		if math.Abs(float64(counts[bit])-want) > want*0.05 {
+			t.Errorf("BitSet.RandomSetBit() chose bit %d %d times, want about %.0f", bit, counts[bit], want)
+		}
+	}
+}
+
+func TestBitSet_Or_EqualLength(t *testing.T) {
+	a := NewBitSetWithInitialSize(10)
+	b := NewBitSetWithInitialSize(90)
+
+	aToSet := []int{1}
+	bToSet := []int{0, 2, 4, 6, 45}
+	a.SetBits(aToSet)
+	b.SetBits(bToSet)
+	a.Or(b)
+	fmt.Println(a)
+}
+
+func TestBitSet_Or_SmallerReceiver(t *testing.T) {
+	a := NewBitSetWithInitialSize(80)
+	b := NewBitSetWithInitialSize(200)
+
+	aToSet := []int{1}
+	bToSet := []int{0, 2, 4, 6, 64}
+	a.SetBits(aToSet)
+	b.SetBits(bToSet)
+	str := b.String()
+	fmt.Println("b:", str, len(str), b.words)
+	//fmt.Println("a:", a, len(a.String()), "b:", b, len(b.String()), b.words)
+	a.Or(b)
+	fmt.Println(a)
+}
+
+func TestBitSet_Or_GrowsReceiver(t *testing.T) {
+	a := NewBitSetWithInitialSize(64)
+	b := NewBitSetWithInitialSize(256)
+
+	a.SetBits([]int{1, 63})
+	b.SetBits([]int{0, 200})
+	a.Or(b)
+
+	if a.Size() != b.Size() {
+		t.Errorf("BitSet.Or(): Size() == %d, want %d", a.Size(), b.Size())
+	}
+	bools, numSet := a.TestBits([]int{0, 1, 63, 200})
+	if numSet != 4 {
+		t.Errorf("BitSet.Or(): want [true, true, true, true], got %v", bools)
+	}
+}
+
+func TestBitSet_Or_LargerReceiver(t *testing.T) {
+	a := NewBitSetWithInitialSize(10)
+	b := NewBitSetWithInitialSize(20)
+
+	aToSet := []int{1}
+	bToSet := []int{0, 2, 4, 6, 18}
+	a.SetBits(aToSet)
+	b.SetBits(bToSet)
+	fmt.Println("a:", a, "b:", b)
+	a.Or(b)
+	fmt.Println(a)
+}
+
+func TestBitSet_And_LargerReceiver(t *testing.T) {
+	a := NewBitSetWithInitialSize(80)
+	b := NewBitSetWithInitialSize(156)
+
+	aToSet := []int{1, 5, 10, 15, 17, 29}
+	bToSet := []int{1, 29, 150}
+	a.SetBits(aToSet)
+	b.SetBits(bToSet)
+	str := b.String()
+	fmt.Println("b:", str, len(str), b.words)
+	a.And(b)
+	//res := And(a, b)
+	fmt.Println(a)
+
+}
+
+func TestBitSet_And_Smaller(t *testing.T) {
+	a := NewBitSetWithInitialSize(20)
+	b := NewBitSetWithInitialSize(64)
+
+	aToSet := []int{1, 5, 10, 15, 17}
+	bToSet := []int{1, 5, 10, 15, 17}
+	a.SetBits(aToSet)
+	b.SetBits(bToSet)
+	fmt.Println("b arr:", b.words)
+	fmt.Println("a:", a, "b: ", b)
+	a.And(b)
+	//res := And(a, b)
+	fmt.Printf("%b\n", 0b00101000010000100010&0b1001001001001)
+	fmt.Println(a)
+}
+
+func TestBitSet_Xor_Smaller(t *testing.T) {
+	a := NewBitSetWithInitialSize(20)
+	b := NewBitSetWithInitialSize(64)
+
+	a.Not()
+	b.Not()
+	aToClear := []int{1, 5, 10, 15, 17}
+	a.ClearBits(aToClear)
+	fmt.Println(a, b)
+	a.Xor(b)
+	fmt.Println(a)
+}
+
+func TestBitSet_SymmetricDifference(t *testing.T) {
+	a, b := NewBitSetWithInitialSize(64), NewBitSetWithInitialSize(256)
+	a.SetBits([]int{1, 5})
+	b.SetBits([]int{5, 200})
+	a.SymmetricDifference(b)
+
+	if a.Size() != 256 || !a.Test(200) {
+		t.Errorf("BitSet.SymmetricDifference(): Size() == %d, Test(200) == %v, want 256, true", a.Size(), a.Test(200))
+	}
+	if !slices.Equal(a.ToIndices(), []int{1, 200}) {
+		t.Errorf("BitSet.SymmetricDifference() == %v, want [1 200]", a.ToIndices())
+	}
+
+	c := NewBitSetWithInitialSize(64)
+	c.SetBits([]int{1, 5})
+	c.Xor(b)
+	if !slices.Equal(c.ToIndices(), a.ToIndices()) {
+		t.Errorf("BitSet.Xor() == %v, want %v", c.ToIndices(), a.ToIndices())
+	}
+}
+
+func TestBitSet_NandNor(t *testing.T) {
+	// truth table over bits 0-3: a = 0011, b = 0101
+	a, b := NewBitSetWithInitialSize(4), NewBitSetWithInitialSize(4)
+	a.SetBits([]int{0, 1})
+	b.SetBits([]int{0, 2})
+
+	if res := a.Clone().Nand(b); !slices.Equal(res.ToIndices(), []int{1, 2, 3}) {
+		t.Errorf("BitSet.Nand() == %v, want [1 2 3]", res.ToIndices())
+	}
+	if res := a.Clone().Nor(b); !slices.Equal(res.ToIndices(), []int{3}) {
+		t.Errorf("BitSet.Nor() == %v, want [3]", res.ToIndices())
+	}
+
+	a, b = NewBitSetWithInitialSize(70), NewBitSetWithInitialSize(200)
+	a.SetBits([]int{0, 65})
+	b.SetBits([]int{0, 150})
+	if res := a.Clone().Nand(b); res.CountSetBits() != 69 || res.Test(0) {
+		t.Errorf("BitSet.Nand() on size 70: CountSetBits() == %d, want 69", res.CountSetBits())
+	}
+	if res := a.Clone().Nor(b); res.CountSetBits() != 68 || res.LastSetBit() != 69 {
+		t.Errorf("BitSet.Nor() on size 70: CountSetBits() == %d, LastSetBit() == %d, want 68, 69", res.CountSetBits(), res.LastSetBit())
+	}
+}
+
+func TestBitSet_Not(t *testing.T) {
+	a := NewBitSetWithInitialSize(20)
 
 	aToSet := []int{1, 5, 10, 15, 17}
 	a.SetBits(aToSet)
@@ -316,8 +1425,568 @@ func TestBitSet_Not(t *testing.T) {
 	fmt.Println(a)
 }
 
+func TestBitSet_Not_PartialFinalWord(t *testing.T) {
+	a := NewBitSetWithInitialSize(65)
+	a.Set(0)
+	a.Not()
+
+	if a.Test(0) {
+		t.Errorf("BitSet.Not(): bit 0 still set")
+	}
+	for _, bit := range []int{1, 63, 64} {
+		if !a.Test(bit) {
+			t.Errorf("BitSet.Not(): bit %d not set", bit)
+		}
+	}
+	if count := a.CountSetBits(); count != 64 {
+		t.Errorf("BitSet.Not(): CountSetBits() == %d, want 64", count)
+	}
+
+	b := NewBitSetWithInitialSize(65)
+	res := Not(b)
+	if !res.Test(64) {
+		t.Errorf("Not(): bit 64 not set")
+	}
+	if count := res.CountSetBits(); count != 65 {
+		t.Errorf("Not(): CountSetBits() == %d, want 65", count)
+	}
+}
+
+func TestOr_LargerHasHighBits(t *testing.T) {
+	a := NewBitSetWithInitialSize(64)
+	b := NewBitSetWithInitialSize(256)
+
+	a.SetBits([]int{1, 5})
+	b.SetBits([]int{0, 130, 255})
+
+	for _, res := range []*BitSet{Or(a, b), Or(b, a)} {
+		if res.Size() != 256 {
+			t.Errorf("Or(): Size() == %d, want 256", res.Size())
+		}
+		bools, numSet := res.TestBits([]int{0, 1, 5, 130, 255})
+		if numSet != 5 {
+			t.Errorf("Or(): want [true, true, true, true, true], got %v", bools)
+		}
+	}
+}
+
+func TestAnd_LargerHasHighBits(t *testing.T) {
+	a := NewBitSetWithInitialSize(64)
+	b := NewBitSetWithInitialSize(256)
+
+	a.SetBits([]int{1, 5})
+	b.SetBits([]int{1, 130, 255})
+
+	for _, res := range []*BitSet{And(a, b), And(b, a)} {
+		if res.Size() != 256 {
+			t.Errorf("And(): Size() == %d, want 256", res.Size())
+		}
+		bools, numSet := res.TestBits([]int{1, 5, 130, 255})
+		if numSet != 1 || !bools[0] {
+			t.Errorf("And(): want [true, false, false, false], got %v", bools)
+		}
+	}
+}
+
+func TestBitSet_ToIndices(t *testing.T) {
+	bs := NewBitSetWithInitialSize(200)
+	bitsToSet := []int{150, 0, 63, 64, 5}
+	bs.SetBits(bitsToSet)
+
+	want := []int{0, 5, 63, 64, 150}
+	if indices := bs.ToIndices(); !slices.Equal(indices, want) {
+		t.Errorf("BitSet.ToIndices() == %v, want %v", indices, want)
+	}
+	if indices := NewBitSet().ToIndices(); len(indices) != 0 {
+		t.Errorf("BitSet.ToIndices() on empty bitset == %v, want []", indices)
+	}
+}
+
+func TestBitSet_ShiftLeft(t *testing.T) {
+	tests := []struct {
+		shift int
+		want  []int
+	}{
+		{0, []int{0, 5, 63, 70}},
+		{3, []int{3, 8, 66, 73}},
+		{64, []int{64, 69, 127, 134}},
+		{130, []int{130, 135, 193, 200}},
+	}
+	for _, tt := range tests {
+		bs := NewBitSetWithInitialSize(80)
+		bs.SetBits([]int{0, 5, 63, 70})
+		bs.ShiftLeft(tt.shift)
+
+		if indices := bs.ToIndices(); !slices.Equal(indices, tt.want) {
+			t.Errorf("BitSet.ShiftLeft(%d): got %v, want %v", tt.shift, indices, tt.want)
+		}
+		if bs.Size() != 80+tt.shift {
+			t.Errorf("BitSet.ShiftLeft(%d): Size() == %d, want %d", tt.shift, bs.Size(), 80+tt.shift)
+		}
+	}
+}
+
+func TestBitSet_ShiftRight(t *testing.T) {
+	tests := []struct {
+		shift int
+		want  []int
+	}{
+		{0, []int{0, 5, 63, 70, 150}},
+		{3, []int{2, 60, 67, 147}},
+		{64, []int{6, 86}},
+		{71, []int{79}},
+		{151, []int{}},
+	}
+	for _, tt := range tests {
+		bs := NewBitSetWithInitialSize(160)
+		bs.SetBits([]int{0, 5, 63, 70, 150})
+		bs.ShiftRight(tt.shift)
+
+		if indices := bs.ToIndices(); !slices.Equal(indices, tt.want) {
+			t.Errorf("BitSet.ShiftRight(%d): got %v, want %v", tt.shift, indices, tt.want)
+		}
+		if bs.Size() != 160 {
+			t.Errorf("BitSet.ShiftRight(%d): Size() == %d, want 160", tt.shift, bs.Size())
+		}
+	}
+}
+
+func TestOrAndXorInto(t *testing.T) {
+	dst := NewBitSetWithInitialSize(1000)
+	dst.SetAll()
+	words := dst.words
+
+	for i := 0; i < 5; i++ {
+		a, b := NewBitSetWithInitialSize(64+rand.Intn(300)), NewBitSetWithInitialSize(64+rand.Intn(300))
+		for j := 0; j < 50; j++ {
+			a.Set(rand.Intn(a.Size()))
+			b.Set(rand.Intn(b.Size()))
+		}
+
+		OrInto(dst, a, b)
+		if want := Or(a, b); dst.Size() != want.Size() || !slices.Equal(dst.ToIndices(), want.ToIndices()) {
+			t.Errorf("OrInto() == %v, want %v", dst.ToIndices(), want.ToIndices())
+		}
+		AndInto(dst, a, b)
+		if want := And(a, b); dst.Size() != want.Size() || !slices.Equal(dst.ToIndices(), want.ToIndices()) {
+			t.Errorf("AndInto() == %v, want %v", dst.ToIndices(), want.ToIndices())
+		}
+		XorInto(dst, a, b)
+		if want := Xor(a, b); dst.Size() != want.Size() || !slices.Equal(dst.ToIndices(), want.ToIndices()) {
+			t.Errorf("XorInto() == %v, want %v", dst.ToIndices(), want.ToIndices())
+		}
+		if &dst.words[0] != &words[0] {
+			t.Errorf("XorInto() reallocated dst's words despite enough capacity")
+		}
+
+		want := Or(a, b)
+		OrInto(a, a, b)
+		if !slices.Equal(a.ToIndices(), want.ToIndices()) {
+			t.Errorf("OrInto() with dst == a == %v, want %v", a.ToIndices(), want.ToIndices())
+		}
+	}
+}
+
+func TestUnion(t *testing.T) {
+	a, b, c := NewBitSetWithInitialSize(10), NewBitSetWithInitialSize(100), NewBitSetWithInitialSize(300)
+	a.SetBits([]int{1, 9})
+	b.SetBits([]int{9, 64, 99})
+	c.SetBits([]int{0, 200, 299})
+
+	res := Union(a, b, c)
+	if res.Size() != 300 {
+		t.Errorf("Union(): Size() == %d, want 300", res.Size())
+	}
+	want := []int{0, 1, 9, 64, 99, 200, 299}
+	if indices := res.ToIndices(); !slices.Equal(indices, want) {
+		t.Errorf("Union() == %v, want %v", indices, want)
+	}
+
+	res = Union()
+	if res.Size() != 0 || res.Any() {
+		t.Errorf("Union() with no bitsets: Size() == %d, Any() == %v, want 0, false", res.Size(), res.Any())
+	}
+}
+
+func TestIntersection(t *testing.T) {
+	a, b, c := NewBitSetWithInitialSize(200), NewBitSetWithInitialSize(100), NewBitSetWithInitialSize(300)
+	a.SetBits([]int{1, 9, 64, 99, 150})
+	b.SetBits([]int{1, 9, 64, 70})
+	c.SetBits([]int{0, 9, 64, 99, 150, 299})
+
+	res := Intersection(a, b, c)
+	if res.Size() != 100 {
+		t.Errorf("Intersection(): Size() == %d, want 100", res.Size())
+	}
+	want := []int{9, 64}
+	if indices := res.ToIndices(); !slices.Equal(indices, want) {
+		t.Errorf("Intersection() == %v, want %v", indices, want)
+	}
+
+	res = Intersection(a)
+	res.Set(0)
+	if a.Test(0) || !slices.Equal(res.ToIndices(), []int{0, 1, 9, 64, 99, 150}) {
+		t.Errorf("Intersection() with one bitset didn't return an independent clone")
+	}
+
+	res = Intersection()
+	if res.Size() != 0 || res.Any() {
+		t.Errorf("Intersection() with no bitsets: Size() == %d, Any() == %v, want 0, false", res.Size(), res.Any())
+	}
+}
+
+func TestBitSet_AndNot(t *testing.T) {
+	a, b := NewBitSetWithInitialSize(200), NewBitSetWithInitialSize(64)
+	a.SetBits([]int{1, 5, 63, 150})
+	b.SetBits([]int{5, 63})
+	a.AndNot(b)
+
+	if !slices.Equal(a.ToIndices(), []int{1, 150}) {
+		t.Errorf("BitSet.AndNot() == %v, want [1 150]", a.ToIndices())
+	}
+}
+
+func TestDifference(t *testing.T) {
+	a, b := NewBitSetWithInitialSize(200), NewBitSetWithInitialSize(300)
+	for i := 0; i < 60; i++ {
+		a.Set(rand.Intn(200))
+		b.Set(rand.Intn(300))
+	}
+	aIndices := a.ToIndices()
+	want := a.Clone().AndNot(b).ToIndices()
+
+	diff := Difference(a, b)
+	if !slices.Equal(diff.ToIndices(), want) || diff.Size() != a.Size() {
+		t.Errorf("Difference() == %v (size %d), want %v (size %d)", diff.ToIndices(), diff.Size(), want, a.Size())
+	}
+	if !slices.Equal(a.ToIndices(), aIndices) {
+		t.Errorf("Difference() mutated its inputs")
+	}
+
+	if got := a.Difference(b).ToIndices(); !slices.Equal(got, want) {
+		t.Errorf("BitSet.Difference() == %v, want %v", got, want)
+	}
+}
+
+func TestBitSet_Merge(t *testing.T) {
+	a, b := NewBitSetWithInitialSize(200), NewBitSetWithInitialSize(300)
+	for i := 0; i < 60; i++ {
+		a.Set(rand.Intn(200))
+		b.Set(rand.Intn(300))
+	}
+	tests := []struct {
+		op   Op
+		want *BitSet
+	}{
+		{OpAnd, a.Clone().And(b)},
+		{OpOr, a.Clone().Or(b)},
+		{OpXor, a.Clone().Xor(b)},
+		{OpAndNot, a.Clone().AndNot(b)},
+	}
+	for _, tt := range tests {
+		got := a.Clone().Merge(b, tt.op)
+		if !slices.Equal(got.ToIndices(), tt.want.ToIndices()) || got.Size() != tt.want.Size() {
+			t.Errorf("BitSet.Merge() with Op %d == %v (size %d), want %v (size %d)", tt.op, got.ToIndices(), got.Size(), tt.want.ToIndices(), tt.want.Size())
+		}
+	}
+
+	// a larger receiver must lose the bits beyond other, as with AndInto
+	x, y := NewBitSetWithInitialSize(250), NewBitSetWithInitialSize(150)
+	x.SetBits([]int{1, 240})
+	y.Set(1)
+	want := NewBitSet()
+	AndInto(want, x, y)
+	if got := x.Clone().Merge(y, OpAnd); !slices.Equal(got.ToIndices(), want.ToIndices()) {
+		t.Errorf("BitSet.Merge() with OpAnd and a larger receiver == %v, want %v", got.ToIndices(), want.ToIndices())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("BitSet.Merge() with unknown Op didn't panic")
+		}
+	}()
+	a.Merge(b, Op(42))
+}
+
+func TestBitSet_Chaining(t *testing.T) {
+	b := NewBitSetWithInitialSize(100)
+	b.SetBits([]int{7, 90})
+	c := NewBitSetWithInitialSize(100)
+	c.SetBits([]int{5, 90})
+
+	chained := NewBitSetWithInitialSize(100)
+	chained.Set(3).Set(5).Flip(6).Clear(3).Or(b).AndNot(c).Xor(b).Not().And(b)
+
+	separate := NewBitSetWithInitialSize(100)
+	separate.Set(3)
+	separate.Set(5)
+	separate.Flip(6)
+	separate.Clear(3)
+	separate.Or(b)
+	separate.AndNot(c)
+	separate.Xor(b)
+	separate.Not()
+	separate.And(b)
+
+	if !slices.Equal(chained.ToIndices(), separate.ToIndices()) {
+		t.Errorf("chained operations == %v, want %v", chained.ToIndices(), separate.ToIndices())
+	}
+	if !slices.Equal(chained.ToIndices(), []int{7}) {
+		t.Errorf("chained operations == %v, want [7]", chained.ToIndices())
+	}
+}
+
+func TestBitSet_InsertBit(t *testing.T) {
+	bs := NewBitSetWithInitialSize(128)
+	bs.SetBits([]int{0, 5, 63, 64, 127})
+
+	bs.InsertBit(5, true)
+	if want := []int{0, 5, 6, 64, 65, 128}; bs.Size() != 129 || !slices.Equal(bs.ToIndices(), want) {
+		t.Errorf("BitSet.InsertBit(5, true) == %v (size %d), want %v (size 129)", bs.ToIndices(), bs.Size(), want)
+	}
+	bs.InsertBit(0, false)
+	if want := []int{1, 6, 7, 65, 66, 129}; bs.Size() != 130 || !slices.Equal(bs.ToIndices(), want) {
+		t.Errorf("BitSet.InsertBit(0, false) == %v (size %d), want %v (size 130)", bs.ToIndices(), bs.Size(), want)
+	}
+	bs.InsertBit(130, true)
+	if bs.Size() != 131 || !bs.Test(130) {
+		t.Errorf("BitSet.InsertBit(130, true) at the end: Size() == %d, Test(130) == %v, want 131, true", bs.Size(), bs.Test(130))
+	}
+}
+
+func TestBitSet_DeleteBit(t *testing.T) {
+	bs := NewBitSetWithInitialSize(130)
+	bs.SetBits([]int{0, 5, 6, 63, 64, 129})
+
+	bs.DeleteBit(5)
+	if want := []int{0, 5, 62, 63, 128}; bs.Size() != 129 || !slices.Equal(bs.ToIndices(), want) {
+		t.Errorf("BitSet.DeleteBit(5) == %v (size %d), want %v (size 129)", bs.ToIndices(), bs.Size(), want)
+	}
+	bs.DeleteBit(63)
+	if want := []int{0, 5, 62, 127}; bs.Size() != 128 || !slices.Equal(bs.ToIndices(), want) {
+		t.Errorf("BitSet.DeleteBit(63) == %v (size %d), want %v (size 128)", bs.ToIndices(), bs.Size(), want)
+	}
+	bs.DeleteBit(128)
+	if bs.Size() != 128 {
+		t.Errorf("BitSet.DeleteBit(128) out of range changed Size() to %d", bs.Size())
+	}
+
+	bs.InsertBit(10, true).DeleteBit(10)
+	if want := []int{0, 5, 62, 127}; !slices.Equal(bs.ToIndices(), want) {
+		t.Errorf("BitSet.InsertBit() then DeleteBit() == %v, want %v", bs.ToIndices(), want)
+	}
+}
+
+func TestBitSet_Concat(t *testing.T) {
+	a, b := NewBitSetWithInitialSize(10), NewBitSetWithInitialSize(70)
+	a.SetBits([]int{0, 9})
+	b.SetBits([]int{0, 3, 60, 69})
+	a.Concat(b)
+
+	if a.Size() != 80 {
+		t.Errorf("BitSet.Concat(): Size() == %d, want 80", a.Size())
+	}
+	if want := []int{0, 9, 10, 13, 70, 79}; !slices.Equal(a.ToIndices(), want) {
+		t.Errorf("BitSet.Concat() == %v, want %v", a.ToIndices(), want)
+	}
+
+	a.Concat(a)
+	if want := []int{0, 9, 10, 13, 70, 79, 80, 89, 90, 93, 150, 159}; a.Size() != 160 || !slices.Equal(a.ToIndices(), want) {
+		t.Errorf("BitSet.Concat() with itself == %v (size %d), want %v (size 160)", a.ToIndices(), a.Size(), want)
+	}
+}
+
+func TestBitSet_SubSet(t *testing.T) {
+	bs := NewBitSetWithInitialSize(200)
+	bs.SetBits([]int{5, 59, 60, 64, 100, 129, 130, 199})
+
+	res := bs.SubSet(60, 130)
+	if res.Size() != 70 {
+		t.Errorf("BitSet.SubSet(60, 130): Size() == %d, want 70", res.Size())
+	}
+	if want := []int{0, 4, 40, 69}; !slices.Equal(res.ToIndices(), want) {
+		t.Errorf("BitSet.SubSet(60, 130) == %v, want %v", res.ToIndices(), want)
+	}
+
+	if res := bs.SubSet(64, 192); !slices.Equal(res.ToIndices(), []int{0, 36, 65, 66}) {
+		t.Errorf("BitSet.SubSet(64, 192) == %v, want [0 36 65 66]", res.ToIndices())
+	}
+	if res := bs.SubSet(50, 50); res.Size() != 0 || res.Any() {
+		t.Errorf("BitSet.SubSet(50, 50): Size() == %d, Any() == %v, want 0, false", res.Size(), res.Any())
+	}
+}
+
+func TestBitSet_ForEachWord(t *testing.T) {
+	bs := NewBitSetWithInitialSize(150)
+	bs.SetBits([]int{0, 63, 64, 127, 149})
+	want := bs.Clone()
+	want.ShiftLeft(1)
+
+	bs.Grow(151)
+	carry := uint64(0)
+	bs.ForEachWord(func(_ int, word uint64) uint64 {
+		shifted := word<<1 | carry
+		carry = word >> 63
+		return shifted
+	})
+	if !slices.Equal(bs.ToIndices(), want.ToIndices()) {
+		t.Errorf("BitSet.ForEachWord() shift == %v, want %v", bs.ToIndices(), want.ToIndices())
+	}
+
+	bs = NewBitSetWithInitialSize(70)
+	bs.ForEachWord(func(int, uint64) uint64 { return ^uint64(0) })
+	if count := bs.CountSetBits(); count != 70 {
+		t.Errorf("BitSet.ForEachWord() setting all bits: CountSetBits() == %d, want 70", count)
+	}
+}
+
+func TestBitSet_ForEachClearBit(t *testing.T) {
+	bs := NewBitSetWithInitialSize(150)
+	bs.SetBits([]int{0, 5, 63, 64, 149})
+
+	var visited []int
+	bs.ForEachClearBit(func(index int) bool {
+		visited = append(visited, index)
+		return true
+	})
+	var want []int
+	for i := 0; i < 150; i++ {
+		if !bs.Test(i) {
+			want = append(want, i)
+		}
+	}
+	if !slices.Equal(visited, want) {
+		t.Errorf("BitSet.ForEachClearBit() visited %v, want %v", visited, want)
+	}
+
+	visited = visited[:0]
+	bs.ForEachClearBit(func(index int) bool {
+		visited = append(visited, index)
+		return len(visited) < 3
+	})
+	if !slices.Equal(visited, []int{1, 2, 3}) {
+		t.Errorf("BitSet.ForEachClearBit() stopping after 3 visited %v, want [1 2 3]", visited)
+	}
+
+	bs.SetAll()
+	bs.ForEachClearBit(func(index int) bool {
+		t.Errorf("BitSet.ForEachClearBit() on full bitset visited %d", index)
+		return true
+	})
+}
+
+func TestBitSet_Rotate(t *testing.T) {
+	bs := NewBitSetWithInitialSize(100)
+	bs.SetBits([]int{0, 1, 63, 99})
+	want := bs.ToIndices()
+
+	if res := bs.Clone().RotateLeft(100); !slices.Equal(res.ToIndices(), want) {
+		t.Errorf("BitSet.RotateLeft(size) == %v, want %v", res.ToIndices(), want)
+	}
+	if res := bs.Clone().RotateRight(100); !slices.Equal(res.ToIndices(), want) {
+		t.Errorf("BitSet.RotateRight(size) == %v, want %v", res.ToIndices(), want)
+	}
+	if res := bs.Clone().RotateLeft(3); res.Size() != 100 || !slices.Equal(res.ToIndices(), []int{2, 3, 4, 66}) {
+		t.Errorf("BitSet.RotateLeft(3) == %v (size %d), want [2 3 4 66] (size 100)", res.ToIndices(), res.Size())
+	}
+	if res := bs.Clone().RotateLeft(203); !slices.Equal(res.ToIndices(), []int{2, 3, 4, 66}) {
+		t.Errorf("BitSet.RotateLeft(203) == %v, want [2 3 4 66]", res.ToIndices())
+	}
+	if res := bs.Clone().RotateRight(2); !slices.Equal(res.ToIndices(), []int{61, 97, 98, 99}) {
+		t.Errorf("BitSet.RotateRight(2) == %v, want [61 97 98 99]", res.ToIndices())
+	}
+	if res := bs.Clone().RotateRight(37).RotateLeft(37); !slices.Equal(res.ToIndices(), want) {
+		t.Errorf("BitSet.RotateRight(37).RotateLeft(37) == %v, want %v", res.ToIndices(), want)
+	}
+}
+
+func TestBitSet_Reverse(t *testing.T) {
+	bs := NewBitSetWithInitialSize(10)
+	bs.SetBits([]int{0, 1, 7})
+	bs.Reverse()
+	if !slices.Equal(bs.ToIndices(), []int{2, 8, 9}) {
+		t.Errorf("BitSet.Reverse() on size 10 == %v, want [2 8 9]", bs.ToIndices())
+	}
+
+	for _, numBits := range []int{1, 64, 70, 200} {
+		bs := NewBitSetWithInitialSize(numBits)
+		for i := 0; i < numBits/2; i++ {
+			bs.Set(rand.Intn(numBits))
+		}
+		want := bs.ToIndices()
+		bs.Reverse()
+		for _, idx := range want {
+			if !bs.Test(numBits - 1 - idx) {
+				t.Errorf("BitSet.Reverse() on size %d: bit %d not moved to %d", numBits, idx, numBits-1-idx)
+			}
+		}
+		if bs.CountSetBits() != len(want) {
+			t.Errorf("BitSet.Reverse() on size %d: CountSetBits() == %d, want %d", numBits, bs.CountSetBits(), len(want))
+		}
+		bs.Reverse()
+		if !slices.Equal(bs.ToIndices(), want) {
+			t.Errorf("BitSet.Reverse() twice on size %d == %v, want %v", numBits, bs.ToIndices(), want)
+		}
+	}
+}
+
+func TestXor(t *testing.T) {
+	a, b := NewBitSetWithInitialSize(64), NewBitSetWithInitialSize(256)
+	a.SetBits([]int{1, 5, 63})
+	b.SetBits([]int{5, 130, 255})
+	aIndices, bIndices := a.ToIndices(), b.ToIndices()
+
+	res := Xor(a, b)
+	want := a.Clone().Xor(b)
+	if res.Size() != want.Size() || !slices.Equal(res.ToIndices(), want.ToIndices()) {
+		t.Errorf("Xor() == %v (size %d), want %v (size %d)", res.ToIndices(), res.Size(), want.ToIndices(), want.Size())
+	}
+	if !slices.Equal(res.ToIndices(), []int{1, 63, 130, 255}) {
+		t.Errorf("Xor() == %v, want [1 63 130 255]", res.ToIndices())
+	}
+	if res = Xor(b, a); !slices.Equal(res.ToIndices(), want.ToIndices()) {
+		t.Errorf("Xor() with swapped arguments == %v, want %v", res.ToIndices(), want.ToIndices())
+	}
+	if !slices.Equal(a.ToIndices(), aIndices) || !slices.Equal(b.ToIndices(), bIndices) {
+		t.Errorf("Xor() mutated its inputs")
+	}
+}
+
+func TestBitSet_Complement(t *testing.T) {
+	bs := NewBitSetWithInitialSize(70)
+	bs.SetBits([]int{0, 10, 64, 69})
+	before := bs.CountSetBits()
+	bs.Complement()
+
+	if count := bs.CountSetBits(); count != 70-before {
+		t.Errorf("BitSet.Complement(): CountSetBits() == %d, want %d", count, 70-before)
+	}
+	if bs.Test(64) || !bs.Test(65) || bs.words[1]>>6 != 0 {
+		t.Errorf("BitSet.Complement(): final word == %b, want bits 65-68 set only", bs.words[1])
+	}
+}
+
+func TestBitSet_FlipAll(t *testing.T) {
+	for _, size := range []int{1, 63, 64, 65, 130} {
+		bs := NewBitSetWithInitialSize(size)
+		for i := 0; i < size; i += 3 {
+			bs.Set(i)
+		}
+		before := bs.CountSetBits()
+		bs.FlipAll()
+
+		if count := bs.CountSetBits(); count != size-before {
+			t.Errorf("BitSet.FlipAll() on size %d: CountSetBits() == %d, want %d", size, count, size-before)
+		}
+		last := bs.words[len(bs.words)-1]
+		if rem := size % 64; rem != 0 && last>>rem != 0 {
+			t.Errorf("BitSet.FlipAll() on size %d: final word == %b, want no bits beyond size", size, last)
+		}
+	}
+}
+
 func TestBitSet_String(t *testing.T) {
-	numBits := rand.Intn(7)
+	numBits := 1 + rand.Intn(7)
 	numBitsToSet := rand.Intn(numBits)
 	bits, setBits := make([]int, numBitsToSet), make(map[int]bool)
 	for i := 0; i < numBitsToSet; i++ {
@@ -329,12 +1998,201 @@ func TestBitSet_String(t *testing.T) {
 	str := bs.String()
 	fmt.Println()
 	for i := len(str) - 1; i >= 0; i-- {
-		_, ok := setBits[i]
+		bit := len(str) - 1 - i
+		_, ok := setBits[bit]
 		if str[i] == '1' && !ok {
-			t.Errorf("SetBits: failed for bit %d, is %d but want %d", i, int(str[i]-'0'), 0)
+			t.Errorf("SetBits: failed for bit %d, is %d but want %d", bit, int(str[i]-'0'), 0)
 		}
 		if str[i] == '0' && ok {
-			t.Errorf("SetBits: failed for bit %d, is %d but want %d", i, int(str[i]-'0'), 1)
+			t.Errorf("SetBits: failed for bit %d, is %d but want %d", bit, int(str[i]-'0'), 1)
+		}
+	}
+}
+
+func TestBitSet_String_Empty(t *testing.T) {
+	if str := NewBitSet().String(); str != "0" {
+		t.Errorf("NewBitSet().String() == %q, want \"0\"", str)
+	}
+	if str := NewBitSetWithInitialSize(0).String(); str != "0" {
+		t.Errorf("BitSet.String() on size 0 == %q, want \"0\"", str)
+	}
+	bs := NewBitSetWithInitialSize(200)
+	bs.Set(130)
+	bs.Clear(130)
+	if str := bs.String(); str != "0" {
+		t.Errorf("BitSet.String() after clearing every bit == %q, want \"0\"", str)
+	}
+	bs.Set(2)
+	if str := bs.String(); str != "100" {
+		t.Errorf("BitSet.String() with bit 2 set == %q, want \"100\"", str)
+	}
+}
+
+func TestBitSet_StringN(t *testing.T) {
+	for _, numBits := range []int{0, 1, 7, 64, 100, 128} {
+		bs := NewBitSetWithInitialSize(numBits)
+		if numBits > 0 {
+			bs.SetBits([]int{0, numBits - 1})
+		}
+		str := bs.StringN()
+		if len(str) != numBits {
+			t.Errorf("BitSet.StringN() on size %d has length %d, want %d", numBits, len(str), numBits)
+		}
+		for i := range str {
+			want := byte('0')
+			if i == 0 || i == numBits-1 {
+				want = '1'
+			}
+			if str[i] != want {
+				t.Errorf("BitSet.StringN() on size %d: position %d is %c, want %c", numBits, i, str[i], want)
+			}
+		}
+	}
+
+	bs := NewBitSetWithInitialSize(128)
+	bs.Set(0)
+	if str := bs.StringN(); str != strings.Repeat("0", 127)+"1" {
+		t.Errorf("BitSet.StringN() == %q, want 127 zeros followed by 1", str)
+	}
+}
+
+func TestBitSet_StringGrouped(t *testing.T) {
+	bs := NewBitSetWithInitialSize(12)
+	bs.SetBits([]int{0, 1, 5, 11})
+	str := bs.StringGrouped(4, " ")
+	if want := "1000 0010 0011"; str != want {
+		t.Errorf("BitSet.StringGrouped(4, \" \") == %q, want %q", str, want)
+	}
+	if groups := strings.Split(str, " "); len(groups) != 3 {
+		t.Errorf("BitSet.StringGrouped(4, \" \") has %d groups, want 3", len(groups))
+	}
+
+	tests := []struct {
+		groupSize int
+		sep       string
+		want      string
+	}{
+		{5, "_", "10_00001_00011"},
+		{12, " ", "100000100011"},
+		{0, " ", "100000100011"},
+		{1, ",", "1,0,0,0,0,0,1,0,0,0,1,1"},
+	}
+	for _, tt := range tests {
+		if got := bs.StringGrouped(tt.groupSize, tt.sep); got != tt.want {
+			t.Errorf("BitSet.StringGrouped(%d, %q) == %q, want %q", tt.groupSize, tt.sep, got, tt.want)
+		}
+	}
+	if got := NewBitSetWithInitialSize(0).StringGrouped(4, " "); got != "" {
+		t.Errorf("BitSet.StringGrouped() on empty bitset == %q, want \"\"", got)
+	}
+}
+
+func TestBitSet_Format(t *testing.T) {
+	bs := NewBitSetWithInitialSize(128)
+	bs.SetBits([]int{0, 2, 64, 127})
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%b", bs.String()},
+		{"%x", "80000000000000010000000000000005"},
+		{"%X", "80000000000000010000000000000005"},
+		{"%s", "[0 2 64 127]"},
+		{"%v", "[0 2 64 127]"},
+		{"%+v", "128:[0 2 64 127]"},
+		{"%+b", "128:" + bs.String()},
+		{"%d", "%!d(*bitset.BitSet=" + bs.String() + ")"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf(tt.format, bs); got != tt.want {
+			t.Errorf("fmt.Sprintf(%q) == %q, want %q", tt.format, got, tt.want)
+		}
+	}
+
+	bs = NewBitSetWithInitialSize(64)
+	bs.SetBits([]int{4, 63})
+	if got, want := fmt.Sprintf("%X", bs), "8000000000000010"; got != want {
+		t.Errorf("fmt.Sprintf(%%X) == %q, want %q", got, want)
+	}
+	bs = NewBitSetWithInitialSize(10)
+	bs.Set(11)
+	if got, want := fmt.Sprintf("%x", bs), "800"; got != want {
+		t.Errorf("fmt.Sprintf(%%x) == %q, want %q", got, want)
+	}
+}
+
+func TestParseBinaryString(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		numBits := rand.Intn(300)
+		bs := NewBitSetWithInitialSize(numBits)
+		for j := 0; j < numBits/3; j++ {
+			bs.Set(rand.Intn(numBits))
+		}
+		res, err := ParseBinaryString(bs.StringN())
+		if err != nil {
+			t.Fatalf("ParseBinaryString(%q) returned error: %v", bs.StringN(), err)
+		}
+		if res.Size() != numBits || !slices.Equal(res.ToIndices(), bs.ToIndices()) {
+			t.Errorf("ParseBinaryString(%q) == %v (size %d), want %v (size %d)", bs.StringN(), res.ToIndices(), res.Size(), bs.ToIndices(), numBits)
+		}
+	}
+
+	res, err := ParseBinaryString("0110")
+	if err != nil || res.Size() != 4 || !slices.Equal(res.ToIndices(), []int{1, 2}) {
+		t.Errorf("ParseBinaryString(\"0110\") == %v, %v, want [1 2], nil", res, err)
+	}
+	for _, str := range []string{"012", "1 0", "x"} {
+		if _, err := ParseBinaryString(str); err == nil {
+			t.Errorf("ParseBinaryString(%q) returned nil error", str)
+		}
+	}
+}
+
+func TestNewBitSetFromIndicesStrict(t *testing.T) {
+	bs, err := NewBitSetFromIndicesStrict([]int{0, 64, 5, 99, 5}, 100)
+	if err != nil {
+		t.Fatalf("NewBitSetFromIndicesStrict() returned error: %v", err)
+	}
+	if bs.Size() != 100 || !slices.Equal(bs.ToIndices(), []int{0, 5, 64, 99}) {
+		t.Errorf("NewBitSetFromIndicesStrict() == %v (size %d), want [0 5 64 99] (size 100)", bs.ToIndices(), bs.Size())
+	}
+
+	tests := []struct {
+		indices []int
+		size    int
+	}{
+		{[]int{1, -1, 3}, 100},
+		{[]int{1, 100}, 100},
+		{[]int{0}, 0},
+		{nil, -1},
+	}
+	for _, tt := range tests {
+		if bs, err := NewBitSetFromIndicesStrict(tt.indices, tt.size); err == nil || bs != nil {
+			t.Errorf("NewBitSetFromIndicesStrict(%v, %d) == %v, %v, want nil, error", tt.indices, tt.size, bs, err)
+		}
+	}
+}
+
+func TestBitSet_ToHex(t *testing.T) {
+	bs := NewBitSetWithInitialSize(128)
+	bs.SetBits([]int{0, 9, 64, 127})
+
+	str := bs.ToHex()
+	if want := "01020000000000000100000000000080"; str != want {
+		t.Errorf("BitSet.ToHex() == %q, want %q", str, want)
+	}
+	res, err := NewBitSetFromHex(str)
+	if err != nil {
+		t.Fatalf("NewBitSetFromHex(%q) returned error: %v", str, err)
+	}
+	if res.Size() != 128 || !slices.Equal(res.ToIndices(), bs.ToIndices()) {
+		t.Errorf("NewBitSetFromHex(%q) == %v (size %d), want %v (size 128)", str, res.ToIndices(), res.Size(), bs.ToIndices())
+	}
+
+	for _, str := range []string{"abc", "zz", "0g"} {
+		if _, err := NewBitSetFromHex(str); err == nil {
+			t.Errorf("NewBitSetFromHex(%q) returned nil error", str)
 		}
 	}
 }
@@ -360,6 +2218,396 @@ func TestBitSet_Count(t *testing.T) {
 	}
 }
 
+func TestBitSet_CountSetBitsParallel(t *testing.T) {
+	for _, numBits := range []int{100, 64 * parallelCountThreshold, 64*parallelCountThreshold*3 + 17} {
+		bs := NewBitSetWithInitialSize(numBits)
+		for i := 0; i < numBits/10; i++ {
+			bs.Set(rand.Intn(numBits))
+		}
+		if count, want := bs.CountSetBitsParallel(), bs.CountSetBits(); count != want {
+			t.Errorf("BitSet.CountSetBitsParallel() on size %d == %d, want %d", numBits, count, want)
+		}
+	}
+}
+
+func TestBitSet_IsEmptyAndCardinality(t *testing.T) {
+	for _, numBits := range []int{1, 64, 200} {
+		for _, numToSet := range []int{0, 1, numBits / 3} {
+			bs := NewBitSetWithInitialSize(numBits)
+			for i := 0; i < numToSet; i++ {
+				bs.Set(rand.Intn(numBits))
+			}
+			if got, want := bs.IsEmpty(), bs.None(); got != want {
+				t.Errorf("BitSet.IsEmpty() on %v == %v, want %v", bs.ToIndices(), got, want)
+			}
+			if got, want := bs.Cardinality(), bs.CountSetBits(); got != want {
+				t.Errorf("BitSet.Cardinality() on %v == %d, want %d", bs.ToIndices(), got, want)
+			}
+		}
+	}
+}
+
+func BenchmarkBitSet_CountSetBits(b *testing.B) {
+	bs := NewBitSetWithInitialSize(1 << 24)
+	bs.SetAll()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bs.CountSetBits()
+	}
+}
+
+func BenchmarkBitSet_CountSetBitsParallel(b *testing.B) {
+	bs := NewBitSetWithInitialSize(1 << 24)
+	bs.SetAll()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bs.CountSetBitsParallel()
+	}
+}
+
+func TestBitSet_CountClearBits(t *testing.T) {
+	bs := NewBitSetWithInitialSize(70)
+	bs.SetBits([]int{0, 10, 64, 69})
+
+	if count := bs.CountClearBits(); count != 66 {
+		t.Errorf("BitSet.CountClearBits() == %d, want 66", count)
+	}
+	if total := bs.CountSetBits() + bs.CountClearBits(); total != 70 {
+		t.Errorf("BitSet.CountSetBits() + CountClearBits() == %d, want 70", total)
+	}
+	if count := NewBitSetWithInitialSize(0).CountClearBits(); count != 0 {
+		t.Errorf("BitSet.CountClearBits() on empty bitset == %d, want 0", count)
+	}
+}
+
+func TestBitSet_CountMatching(t *testing.T) {
+	for _, numBits := range []int{0, 1, 64, 70, 300} {
+		bs := NewBitSetWithInitialSize(numBits)
+		for i := 0; i < numBits; i += 3 {
+			bs.Set(i)
+		}
+		if got, want := bs.CountMatching(0), bs.CountClearBits(); got != want {
+			t.Errorf("BitSet.CountMatching(0) on size %d == %d, want %d", numBits, got, want)
+		}
+		if got, want := bs.CountMatching(^uint64(0)), bs.CountSetBits(); got != want {
+			t.Errorf("BitSet.CountMatching(^0) on size %d == %d, want %d", numBits, got, want)
+		}
+	}
+
+	bs := NewBitSetWithInitialSize(70)
+	bs.SetWord(0, 0xFF)
+	bs.Set(64)
+	// word 0 matches 0xF0 in bits 4-63, word 1 (6 bits) matches in bits 1-3
+	if got, want := bs.CountMatching(0xF0), 60+3; got != want {
+		t.Errorf("BitSet.CountMatching(0xF0) == %d, want %d", got, want)
+	}
+}
+
+func TestBitSet_BoolSlice(t *testing.T) {
+	values := make([]bool, 150)
+	for i := range values {
+		values[i] = rand.Intn(2) == 1
+	}
+
+	bs := NewBitSetFromBoolSlice(values)
+	if bs.Size() != len(values) {
+		t.Errorf("NewBitSetFromBoolSlice(): Size() == %d, want %d", bs.Size(), len(values))
+	}
+	if res := bs.ToBoolSlice(); !slices.Equal(res, values) {
+		t.Errorf("BitSet.ToBoolSlice() == %v, want %v", res, values)
+	}
+	if res := NewBitSetFromBoolSlice(nil).ToBoolSlice(); len(res) != 0 {
+		t.Errorf("BitSet.ToBoolSlice() on empty bitset == %v, want []", res)
+	}
+}
+
+func TestBitSet_Density(t *testing.T) {
+	bs := NewBitSetWithInitialSize(100)
+	for i := 0; i < 100; i += 2 {
+		bs.Set(i)
+	}
+	if density := bs.Density(); math.Abs(density-0.5) > 1e-9 {
+		t.Errorf("BitSet.Density() on half-full bitset == %f, want 0.5", density)
+	}
+
+	bs = NewBitSetWithInitialSize(0)
+	if density := bs.Density(); density != 0 {
+		t.Errorf("BitSet.Density() on empty bitset == %f, want 0", density)
+	}
+}
+
+func TestBitSet_Equal(t *testing.T) {
+	a, b := NewBitSetWithInitialSize(64), NewBitSetWithInitialSize(1000)
+	a.SetBits([]int{1, 63})
+	b.SetBits([]int{1, 63})
+
+	if !a.Equal(b) || !b.Equal(a) {
+		t.Errorf("BitSet.Equal() on content-identical bitsets of different sizes == false, want true")
+	}
+	b.Set(500)
+	if a.Equal(b) || b.Equal(a) {
+		t.Errorf("BitSet.Equal() on different bitsets == true, want false")
+	}
+}
+
+func TestBitSet_EqualStrict(t *testing.T) {
+	a, b := NewBitSetWithInitialSize(64), NewBitSetWithInitialSize(128)
+	a.SetBits([]int{1, 63})
+	b.SetBits([]int{1, 63})
+	if a.EqualStrict(b) || b.EqualStrict(a) {
+		t.Errorf("BitSet.EqualStrict() on content-identical bitsets of sizes 64 and 128 == true, want false")
+	}
+
+	a, b = NewBitSetWithInitialSize(0), NewBitSetWithInitialSize(1)
+	if a.EqualStrict(b) || b.EqualStrict(a) {
+		t.Errorf("BitSet.EqualStrict() on empty bitsets of sizes 0 and 1 == true, want false")
+	}
+
+	a, b = NewBitSetWithInitialSize(100), NewBitSetWithInitialSize(100)
+	a.SetBits([]int{0, 99})
+	b.SetBits([]int{0, 99})
+	if !a.EqualStrict(b) {
+		t.Errorf("BitSet.EqualStrict() on identical bitsets == false, want true")
+	}
+	b.Clear(99)
+	if a.EqualStrict(b) {
+		t.Errorf("BitSet.EqualStrict() on different bitsets of the same size == true, want false")
+	}
+}
+
+func TestBitSet_Compare(t *testing.T) {
+	newSet := func(size int, indices ...int) *BitSet {
+		bs := NewBitSetWithInitialSize(size)
+		bs.SetBits(indices)
+		return bs
+	}
+	tests := []struct {
+		name string
+		a, b *BitSet
+		want Relation
+	}{
+		{"equal", newSet(64, 1, 63), newSet(1000, 1, 63), RelEqual},
+		{"both empty", newSet(10), newSet(200), RelEqual},
+		{"proper subset", newSet(64, 1), newSet(200, 1, 150), RelSubset},
+		{"empty subset", newSet(64), newSet(64, 3), RelSubset},
+		{"proper superset", newSet(200, 1, 63, 150), newSet(64, 1, 63), RelSuperset},
+		{"incomparable", newSet(200, 1, 150), newSet(200, 2, 150), RelIncomparable},
+	}
+	for _, tt := range tests {
+		if got := tt.a.Compare(tt.b); got != tt.want {
+			t.Errorf("BitSet.Compare() on %s bitsets == %d, want %d", tt.name, got, tt.want)
+		}
+	}
+	if newSet(64, 1).Compare(newSet(64, 1, 2)) >= 0 || newSet(64, 1, 2).Compare(newSet(64, 1)) <= 0 {
+		t.Errorf("BitSet.Compare() sign: subset is not negative or superset is not positive")
+	}
+}
+
+func TestBitSet_Key(t *testing.T) {
+	a, b := NewBitSetWithInitialSize(64), NewBitSetWithInitialSize(1000)
+	a.SetBits([]int{1, 63})
+	b.SetBits([]int{1, 63})
+	if len(a.words) == len(b.words) {
+		t.Fatalf("test bitsets should have different word-slice lengths")
+	}
+
+	if a.Key() != b.Key() {
+		t.Errorf("BitSet.Key() differs for equal bitsets: %q, %q", a.Key(), b.Key())
+	}
+	seen := map[string]bool{a.Key(): true}
+	b.Set(500)
+	if seen[b.Key()] {
+		t.Errorf("BitSet.Key() is the same for different bitsets")
+	}
+	if NewBitSet().Key() != NewBitSetWithInitialSize(500).Key() {
+		t.Errorf("BitSet.Key() differs for empty bitsets")
+	}
+}
+
+// dedup returns s with elements equal to an earlier element removed.
+func dedup[T any](s []T, eq func(a, b T) bool) []T {
+	res := []T{}
+	for _, v := range s {
+		if !slices.ContainsFunc(res, func(u T) bool { return eq(u, v) }) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func TestEqual(t *testing.T) {
+	sets := make([]*BitSet, 0)
+	for _, size := range []int{10, 64, 200} {
+		for _, indices := range [][]int{{1, 2}, {3}, {1, 2}} {
+			bs := NewBitSetWithInitialSize(size)
+			bs.SetBits(indices)
+			sets = append(sets, bs)
+		}
+	}
+
+	res := dedup(sets, Equal)
+	if len(res) != 2 {
+		t.Fatalf("dedup() with Equal left %d bitsets, want 2", len(res))
+	}
+	if !slices.Equal(res[0].ToIndices(), []int{1, 2}) || !slices.Equal(res[1].ToIndices(), []int{3}) {
+		t.Errorf("dedup() with Equal == [%v %v], want [[1 2] [3]]", res[0].ToIndices(), res[1].ToIndices())
+	}
+}
+
+func TestBitSet_JaccardSimilarity(t *testing.T) {
+	a, b := NewBitSetWithInitialSize(64), NewBitSetWithInitialSize(200)
+	if sim := a.JaccardSimilarity(b); sim != 1 {
+		t.Errorf("BitSet.JaccardSimilarity() on empty bitsets == %f, want 1", sim)
+	}
+
+	a.SetBits([]int{1, 2, 3})
+	b.SetBits([]int{1, 2, 3})
+	if sim := a.JaccardSimilarity(b); sim != 1 {
+		t.Errorf("BitSet.JaccardSimilarity() on identical bitsets == %f, want 1", sim)
+	}
+
+	b.ClearAll()
+	b.SetBits([]int{4, 150})
+	if sim := a.JaccardSimilarity(b); sim != 0 {
+		t.Errorf("BitSet.JaccardSimilarity() on disjoint bitsets == %f, want 0", sim)
+	}
+
+	// intersection {2, 3}, union {1, 2, 3, 150}
+	b.SetBits([]int{2, 3})
+	b.Clear(4)
+	if sim := a.JaccardSimilarity(b); math.Abs(sim-0.5) > 1e-9 {
+		t.Errorf("BitSet.JaccardSimilarity() == %f, want 0.5", sim)
+	}
+	if sim := b.JaccardSimilarity(a); math.Abs(sim-0.5) > 1e-9 {
+		t.Errorf("BitSet.JaccardSimilarity() == %f, want 0.5", sim)
+	}
+}
+
+func TestBitSet_CountAndOrXor(t *testing.T) {
+	a, b := NewBitSetWithInitialSize(64), NewBitSetWithInitialSize(300)
+	for i := 0; i < 40; i++ {
+		a.Set(rand.Intn(64))
+		b.Set(rand.Intn(300))
+	}
+
+	for _, pair := range [][2]*BitSet{{a, b}, {b, a}} {
+		x, y := pair[0], pair[1]
+		if count, want := x.CountAnd(y), And(x, y).CountSetBits(); count != want {
+			t.Errorf("BitSet.CountAnd() == %d, want %d", count, want)
+		}
+		if count, want := x.CountOr(y), Or(x, y).CountSetBits(); count != want {
+			t.Errorf("BitSet.CountOr() == %d, want %d", count, want)
+		}
+		if count, want := x.CountXor(y), Or(x, y).CountSetBits()-And(x, y).CountSetBits(); count != want {
+			t.Errorf("BitSet.CountXor() == %d, want %d", count, want)
+		}
+	}
+}
+
+func TestBitSet_Diff(t *testing.T) {
+	a, b := NewBitSetWithInitialSize(64), NewBitSetWithInitialSize(300)
+	a.SetBits([]int{1, 63})
+	b.SetBits([]int{1, 63})
+	if distance, equal := a.Diff(b); distance != 0 || !equal {
+		t.Errorf("BitSet.Diff() on equal bitsets == %d, %v, want 0, true", distance, equal)
+	}
+
+	b.SetBits([]int{2, 250})
+	b.Clear(63)
+	if distance, equal := a.Diff(b); distance != 3 || equal {
+		t.Errorf("BitSet.Diff() on differing bitsets == %d, %v, want 3, false", distance, equal)
+	}
+	if distance, equal := b.Diff(a); distance != 3 || equal != a.Equal(b) {
+		t.Errorf("BitSet.Diff() reversed == %d, %v, want 3, %v", distance, equal, a.Equal(b))
+	}
+}
+
+func TestBitSet_Rank(t *testing.T) {
+	numBits := 300
+	bs := NewBitSetWithInitialSize(numBits)
+	for i := 0; i < numBits/2; i++ {
+		bs.Set(rand.Intn(numBits))
+	}
+
+	if rank := bs.Rank(0); rank != 0 {
+		t.Errorf("BitSet.Rank(0) == %d, want 0", rank)
+	}
+	if rank, want := bs.Rank(numBits), bs.CountSetBits(); rank != want {
+		t.Errorf("BitSet.Rank(%d) == %d, want %d", numBits, rank, want)
+	}
+	want := 0
+	for i := 0; i <= numBits; i++ {
+		if rank := bs.Rank(i); rank != want {
+			t.Errorf("BitSet.Rank(%d) == %d, want %d", i, rank, want)
+		}
+		if bs.Test(i) {
+			want++
+		}
+	}
+	if rank := bs.Rank(-5); rank != 0 {
+		t.Errorf("BitSet.Rank(-5) == %d, want 0", rank)
+	}
+	if rank, want := bs.Rank(numBits*2), bs.CountSetBits(); rank != want {
+		t.Errorf("BitSet.Rank(%d) == %d, want %d", numBits*2, rank, want)
+	}
+}
+
+func TestBitSet_Select(t *testing.T) {
+	bs := NewBitSetWithInitialSize(200)
+	bitsToSet := []int{3, 64, 65, 130, 199}
+	bs.SetBits(bitsToSet)
+
+	for k, want := range bitsToSet {
+		if idx := bs.Select(k); idx != want {
+			t.Errorf("BitSet.Select(%d) == %d, want %d", k, idx, want)
+		}
+	}
+	for _, k := range []int{-1, len(bitsToSet), 1000} {
+		if idx := bs.Select(k); idx != -1 {
+			t.Errorf("BitSet.Select(%d) == %d, want -1", k, idx)
+		}
+	}
+	if idx := NewBitSet().Select(0); idx != -1 {
+		t.Errorf("BitSet.Select(0) on empty bitset == %d, want -1", idx)
+	}
+}
+
+func Test_mask(t *testing.T) {
+	word := ^uint64(0)
+	tests := []struct {
+		n    int
+		want uint64
+	}{
+		{-1, 0},
+		{0, 0},
+		{1, 1},
+		{63, 1<<63 - 1},
+		{64, word},
+		{65, word},
+	}
+	for _, tt := range tests {
+		if got := mask(word, tt.n); got != tt.want {
+			t.Errorf("mask(%b, %d) == %b, want %b", word, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestBitSet_Not_NoPhantomBits(t *testing.T) {
+	// 64 bits backed by more than one word; bits past the size must stay clear
+	bs := &BitSet{size: 64, words: make([]uint64, 2)}
+	bs.Not()
+	if count := bs.CountSetBits(); count != 64 {
+		t.Errorf("BitSet.Not() on size 64 with 2 words: CountSetBits() == %d, want 64", count)
+	}
+	if count := Not(bs).CountSetBits(); count != 0 {
+		t.Errorf("Not() on full size 64 bitset with 2 words: CountSetBits() == %d, want 0", count)
+	}
+	bs.Or(NewBitSetWithInitialSize(10).Not())
+	if count := bs.CountSetBits(); count != 64 {
+		t.Errorf("BitSet.Or() on size 64 with 2 words: CountSetBits() == %d, want 64", count)
+	}
+}
+
 func Test_Do(t *testing.T) {
 	fmt.Printf("%b\n", 0b00000|0b1001)
 }
@@ -0,0 +1,78 @@
+package bitset
+
+import "sync"
+
+// ConcurrentBitSet is a BitSet that is safe for use by multiple goroutines. Queries take a read
+// lock and mutations take a write lock.
+type ConcurrentBitSet struct {
+	mu sync.RWMutex
+	bs *BitSet
+}
+
+// NewConcurrentBitSet initializes and returns a ConcurrentBitSet holding the given number of bits.
+func NewConcurrentBitSet(numBits int) *ConcurrentBitSet {
+	return &ConcurrentBitSet{bs: NewBitSetWithInitialSize(numBits)}
+}
+
+// Size returns the number of bits the bitset holds
+func (cbs *ConcurrentBitSet) Size() int {
+	cbs.mu.RLock()
+	defer cbs.mu.RUnlock()
+	return cbs.bs.Size()
+}
+
+// Set sets the Nth bit to 1, growing the bitset if n >= bitset.size. Negative n is ignored.
+func (cbs *ConcurrentBitSet) Set(n int) {
+	cbs.mu.Lock()
+	defer cbs.mu.Unlock()
+	cbs.bs.Set(n)
+}
+
+// Clear zeroes the Nth bit. Negative n is ignored.
+func (cbs *ConcurrentBitSet) Clear(n int) {
+	cbs.mu.Lock()
+	defer cbs.mu.Unlock()
+	cbs.bs.Clear(n)
+}
+
+// Test checks if the Nth bit is set to 1. Returns false if n < 0 or n >= bitset.size.
+func (cbs *ConcurrentBitSet) Test(n int) bool {
+	cbs.mu.RLock()
+	defer cbs.mu.RUnlock()
+	return cbs.bs.Test(n)
+}
+
+// TestAndSet atomically sets the Nth bit to 1 and returns whether it was set before.
+func (cbs *ConcurrentBitSet) TestAndSet(n int) bool {
+	cbs.mu.Lock()
+	defer cbs.mu.Unlock()
+	return cbs.bs.TestAndSet(n)
+}
+
+// TestAndClear atomically zeroes the Nth bit and returns whether it was set before.
+func (cbs *ConcurrentBitSet) TestAndClear(n int) bool {
+	cbs.mu.Lock()
+	defer cbs.mu.Unlock()
+	return cbs.bs.TestAndClear(n)
+}
+
+// CountSetBits returns the number of set bits.
+func (cbs *ConcurrentBitSet) CountSetBits() int {
+	cbs.mu.RLock()
+	defer cbs.mu.RUnlock()
+	return cbs.bs.CountSetBits()
+}
+
+// Any returns true if at least one bit is set
+func (cbs *ConcurrentBitSet) Any() bool {
+	cbs.mu.RLock()
+	defer cbs.mu.RUnlock()
+	return cbs.bs.Any()
+}
+
+// None returns true if no bits are set
+func (cbs *ConcurrentBitSet) None() bool {
+	cbs.mu.RLock()
+	defer cbs.mu.RUnlock()
+	return cbs.bs.None()
+}
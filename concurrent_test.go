@@ -0,0 +1,71 @@
+package bitset
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestConcurrentBitSet_Set(t *testing.T) {
+	cbs := NewConcurrentBitSet(64)
+	numGoroutines, bitsPerGoroutine := 16, 100
+
+	wg := sync.WaitGroup{}
+	for g := 0; g < numGoroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < bitsPerGoroutine; i++ {
+				bit := i*numGoroutines + g
+				cbs.Set(bit)
+				if !cbs.Test(bit) {
+					t.Errorf("ConcurrentBitSet.Test(%d) == false after Set", bit)
+				}
+				cbs.CountSetBits()
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if count, want := cbs.CountSetBits(), numGoroutines*bitsPerGoroutine; count != want {
+		t.Errorf("ConcurrentBitSet.CountSetBits() == %d, want %d", count, want)
+	}
+	if !cbs.Any() || cbs.None() {
+		t.Errorf("ConcurrentBitSet.Any() == %v, None() == %v, want true, false", cbs.Any(), cbs.None())
+	}
+
+	cbs.Clear(0)
+	if cbs.Test(0) {
+		t.Errorf("ConcurrentBitSet.Test(0) == true after Clear, want false")
+	}
+}
+
+func TestConcurrentBitSet_TestAndSet(t *testing.T) {
+	cbs := NewConcurrentBitSet(64)
+	numGoroutines, numSlots := 8, 200
+	claimed := make([]int, numGoroutines)
+
+	wg := sync.WaitGroup{}
+	for g := 0; g < numGoroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for slot := 0; slot < numSlots; slot++ {
+				if !cbs.TestAndSet(slot) {
+					claimed[g]++
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	total := 0
+	for _, n := range claimed {
+		total += n
+	}
+	if total != numSlots {
+		t.Errorf("ConcurrentBitSet.TestAndSet() claimed %d slots, want %d", total, numSlots)
+	}
+	if !cbs.TestAndClear(0) || cbs.TestAndClear(0) {
+		t.Errorf("ConcurrentBitSet.TestAndClear(0) didn't report previous values correctly")
+	}
+}
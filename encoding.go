@@ -0,0 +1,227 @@
+package bitset
+
+import (
+	"bytes"
+	"compress/flate"
+	"database/sql/driver"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler. The encoding is the size of the bitset as a
+// little-endian uint64 followed by the words needed to hold that many bits, also little-endian.
+func (bs *BitSet) MarshalBinary() ([]byte, error) {
+	numWords := (bs.size + 63) / 64
+	data := make([]byte, 8, 8+8*numWords)
+	binary.LittleEndian.PutUint64(data, uint64(bs.size))
+	for i := 0; i < numWords; i++ {
+		data = binary.LittleEndian.AppendUint64(data, bs.getWord(i))
+	}
+	return data, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, decoding data produced by MarshalBinary.
+func (bs *BitSet) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 {
+		return fmt.Errorf("binary data of length %d too short for bitset header", len(data))
+	}
+	size, numWords := binary.LittleEndian.Uint64(data), uint64(len(data)-8)/8
+	if uint64(len(data)-8)%8 != 0 || size > numWords*64 || numWords > 0 && size <= (numWords-1)*64 {
+		return fmt.Errorf("binary data of length %d doesn't match bitset of size %d", len(data), size)
+	}
+	words := make([]uint64, max(1, numWords))
+	for i := range words[:numWords] {
+		words[i] = binary.LittleEndian.Uint64(data[8+8*i:])
+	}
+	if numWords > 0 {
+		words[numWords-1] = mask(words[numWords-1], int(size-(numWords-1)*64))
+	}
+	bs.size, bs.words = int(size), words
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler, encoding the bitset as the fixed-width binary
+// string produced by StringN.
+func (bs *BitSet) MarshalText() ([]byte, error) {
+	return []byte(bs.StringN()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, decoding a binary string of '0' and '1'
+// characters as produced by MarshalText. The size of the bitset becomes the length of the text.
+func (bs *BitSet) UnmarshalText(text []byte) error {
+	res, err := ParseBinaryString(string(text))
+	if err != nil {
+		return err
+	}
+	*bs = *res
+	return nil
+}
+
+// Scanner returns a fmt.Scanner that reads a binary string token, laid out like String and StringN,
+// into the bitset, e.g. fmt.Sscanf("1011", "%b", bs.Scanner()). The %s and %b verbs are supported.
+// The bitset's size becomes the length of the token. BitSet can't implement fmt.Scanner itself
+// since its Scan method implements sql.Scanner.
+func (bs *BitSet) Scanner() fmt.Scanner {
+	return bitSetScanner{bs}
+}
+
+type bitSetScanner struct {
+	bs *BitSet
+}
+
+// Scan implements fmt.Scanner.
+func (s bitSetScanner) Scan(state fmt.ScanState, verb rune) error {
+	if verb != 's' && verb != 'b' {
+		return fmt.Errorf("unsupported verb %%%c for bitset", verb)
+	}
+	token, err := state.Token(true, nil)
+	if err != nil {
+		return err
+	}
+	if len(token) == 0 {
+		return io.ErrUnexpectedEOF
+	}
+	return s.bs.UnmarshalText(token)
+}
+
+// MarshalCompressed encodes the bitset in the format produced by MarshalBinary compressed with
+// DEFLATE, preceded by the uncompressed length as a uvarint.
+func (bs *BitSet) MarshalCompressed() ([]byte, error) {
+	raw, err := bs.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBuffer(binary.AppendUvarint(nil, uint64(len(raw))))
+	w, err := flate.NewWriter(buf, flate.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(raw); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalCompressed decodes data produced by MarshalCompressed.
+func (bs *BitSet) UnmarshalCompressed(data []byte) error {
+	rawLen, n := binary.Uvarint(data)
+	// DEFLATE can't compress by more than a factor of about 1032, so a larger length is corrupt
+	if n <= 0 || rawLen > 1032*uint64(len(data)) {
+		return fmt.Errorf("invalid compressed bitset header")
+	}
+	raw := make([]byte, rawLen)
+	r := flate.NewReader(bytes.NewReader(data[n:]))
+	defer r.Close()
+	if _, err := io.ReadFull(r, raw); err != nil {
+		return fmt.Errorf("decompressing bitset: %w", err)
+	}
+	return bs.UnmarshalBinary(raw)
+}
+
+// maxDecodedSize is the largest bitset size the sparse and run-length decoders accept, 32 MiB of
+// words. Their headers can describe huge bitsets in a few bytes, so this bounds what corrupt or
+// hostile input can make them allocate. It's below math.MaxInt on every platform, so sizes and run
+// lengths within it convert to int without wrapping.
+const maxDecodedSize = 1 << 28
+
+// MarshalSparse encodes the bitset as its size followed by the gaps between consecutive set bits,
+// all as uvarints. Each gap is the number of clear bits since the previous set bit, or since bit 0
+// for the first. This is the smallest encoding for bitsets with few set bits.
+func (bs *BitSet) MarshalSparse() ([]byte, error) {
+	data := binary.AppendUvarint(nil, uint64(bs.size))
+	prev := -1
+	for _, idx := range bs.ToIndices() {
+		data = binary.AppendUvarint(data, uint64(idx-prev-1))
+		prev = idx
+	}
+	return data, nil
+}
+
+// UnmarshalSparse decodes data produced by MarshalSparse.
+func (bs *BitSet) UnmarshalSparse(data []byte) error {
+	size, n := binary.Uvarint(data)
+	if n <= 0 || size > maxDecodedSize {
+		return fmt.Errorf("invalid sparse bitset header")
+	}
+	res := NewBitSetWithInitialSize(int(size))
+	prev := uint64(0) // one past the previous set bit
+	for data = data[n:]; len(data) > 0; data = data[n:] {
+		var gap uint64
+		gap, n = binary.Uvarint(data)
+		if n <= 0 || gap >= size-prev {
+			return fmt.Errorf("invalid sparse bitset data after bit %d", int(prev)-1)
+		}
+		prev += gap + 1
+		res.set(int(prev - 1))
+	}
+	*bs = *res
+	return nil
+}
+
+// RunLengthEncode encodes the bitset as its size followed by the lengths of its alternating runs of
+// clear and set bits, starting with a (possibly empty) run of clear bits, all as uvarints. This is
+// much smaller than MarshalBinary for bitsets made of long runs.
+func (bs *BitSet) RunLengthEncode() []byte {
+	data := binary.AppendUvarint(nil, uint64(bs.size))
+	for i, set := 0, false; i < bs.size; set = !set {
+		next := bs.nextBit(i, !set)
+		data = binary.AppendUvarint(data, uint64(next-i))
+		i = next
+	}
+	return data
+}
+
+// NewBitSetFromRunLength initializes and returns a BitSet from data produced by RunLengthEncode.
+func NewBitSetFromRunLength(data []byte) (*BitSet, error) {
+	size, n := binary.Uvarint(data)
+	if n <= 0 || size > maxDecodedSize {
+		return nil, fmt.Errorf("invalid run-length header")
+	}
+	var runs []uint64
+	total := uint64(0)
+	for data = data[n:]; len(data) > 0; data = data[n:] {
+		var run uint64
+		run, n = binary.Uvarint(data)
+		if n <= 0 || run > size-total {
+			return nil, fmt.Errorf("invalid run-length data at offset %d", total)
+		}
+		runs, total = append(runs, run), total+run
+	}
+	if total != size {
+		return nil, fmt.Errorf("run lengths sum to %d, want bitset size %d", total, size)
+	}
+	bs := NewBitSetWithInitialSize(int(size))
+	i := 0
+	for j, run := range runs {
+		if set := j%2 == 1; set && run > 0 {
+			bs.rangeWords(i, i+int(run), func(wordIdx int, m uint64) { bs.words[wordIdx] |= m })
+		}
+		i += int(run)
+	}
+	return bs, nil
+}
+
+// Value implements driver.Valuer, storing the bitset in the format produced by MarshalBinary.
+func (bs *BitSet) Value() (driver.Value, error) {
+	return bs.MarshalBinary()
+}
+
+// Scan implements sql.Scanner, accepting a []byte or string in the format produced by
+// MarshalBinary. A nil src resets the bitset to an empty one.
+func (bs *BitSet) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		*bs = *NewBitSetWithInitialSize(0)
+		return nil
+	case []byte:
+		return bs.UnmarshalBinary(src)
+	case string:
+		return bs.UnmarshalBinary([]byte(src))
+	default:
+		return fmt.Errorf("cannot scan %T into bitset", src)
+	}
+}
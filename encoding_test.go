@@ -0,0 +1,248 @@
+package bitset
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBitSet_MarshalBinary(t *testing.T) {
+	for _, numBits := range []int{0, 1, 64, 70, 300} {
+		bs := NewBitSetWithInitialSize(numBits)
+		for i := 0; i < numBits; i += 7 {
+			bs.Set(i)
+		}
+		data, err := bs.MarshalBinary()
+		if err != nil {
+			t.Fatalf("BitSet.MarshalBinary() on size %d returned error: %v", numBits, err)
+		}
+
+		res := NewBitSet()
+		if err := res.UnmarshalBinary(data); err != nil {
+			t.Fatalf("BitSet.UnmarshalBinary() on size %d returned error: %v", numBits, err)
+		}
+		if res.Size() != numBits || !slices.Equal(res.ToIndices(), bs.ToIndices()) {
+			t.Errorf("BitSet.UnmarshalBinary() == %v (size %d), want %v (size %d)", res.ToIndices(), res.Size(), bs.ToIndices(), numBits)
+		}
+	}
+
+	bs := NewBitSet()
+	for _, data := range [][]byte{nil, {1, 2, 3}, {65, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0}} {
+		if err := bs.UnmarshalBinary(data); err == nil {
+			t.Errorf("BitSet.UnmarshalBinary(%v) returned nil error", data)
+		}
+	}
+}
+
+func TestBitSet_MarshalText(t *testing.T) {
+	bs := NewBitSetWithInitialSize(70)
+	bs.SetBits([]int{0, 3, 69})
+
+	text, err := bs.MarshalText()
+	if err != nil {
+		t.Fatalf("BitSet.MarshalText() returned error: %v", err)
+	}
+	if string(text) != bs.StringN() {
+		t.Errorf("BitSet.MarshalText() == %q, want %q", text, bs.StringN())
+	}
+	res := NewBitSet()
+	if err := res.UnmarshalText(text); err != nil {
+		t.Fatalf("BitSet.UnmarshalText() returned error: %v", err)
+	}
+	if res.Size() != 70 || !slices.Equal(res.ToIndices(), []int{0, 3, 69}) {
+		t.Errorf("BitSet.UnmarshalText() == %v (size %d), want [0 3 69] (size 70)", res.ToIndices(), res.Size())
+	}
+}
+
+func TestBitSet_UnmarshalText_Invalid(t *testing.T) {
+	bs := NewBitSet()
+	bs.Set(5)
+	err := bs.UnmarshalText([]byte("1021"))
+	if err == nil {
+		t.Fatalf("BitSet.UnmarshalText(\"1021\") returned nil error")
+	}
+	if !strings.Contains(err.Error(), "'2'") {
+		t.Errorf("BitSet.UnmarshalText(\"1021\") error %q doesn't mention the invalid character", err)
+	}
+	if !slices.Equal(bs.ToIndices(), []int{5}) {
+		t.Errorf("BitSet.UnmarshalText() with invalid text modified the bitset to %v", bs.ToIndices())
+	}
+}
+
+func TestBitSet_Scanner(t *testing.T) {
+	bs := NewBitSet()
+	if _, err := fmt.Sscanf("1011", "%b", bs.Scanner()); err != nil {
+		t.Fatalf("fmt.Sscanf(\"1011\", \"%%b\") returned error: %v", err)
+	}
+	if bs.Size() != 4 || !slices.Equal(bs.ToIndices(), []int{0, 1, 3}) {
+		t.Errorf("fmt.Sscanf(\"1011\", \"%%b\") == %v (size %d), want [0 1 3] (size 4)", bs.ToIndices(), bs.Size())
+	}
+
+	a, b := NewBitSet(), NewBitSet()
+	if n, err := fmt.Sscanf("  0110 1", "%s %b", a.Scanner(), b.Scanner()); n != 2 || err != nil {
+		t.Fatalf("fmt.Sscanf(\"  0110 1\", \"%%s %%b\") == %d, %v, want 2, nil", n, err)
+	}
+	if !slices.Equal(a.ToIndices(), []int{1, 2}) || !slices.Equal(b.ToIndices(), []int{0}) {
+		t.Errorf("fmt.Sscanf(\"  0110 1\") == %v, %v, want [1 2], [0]", a.ToIndices(), b.ToIndices())
+	}
+
+	if _, err := fmt.Sscanf("1021", "%b", bs.Scanner()); err == nil {
+		t.Errorf("fmt.Sscanf(\"1021\", \"%%b\") returned nil error")
+	}
+	if _, err := fmt.Sscanf("1011", "%d", bs.Scanner()); err == nil {
+		t.Errorf("fmt.Sscanf(\"1011\", \"%%d\") returned nil error")
+	}
+	if !slices.Equal(bs.ToIndices(), []int{0, 1, 3}) {
+		t.Errorf("failed scans modified the bitset to %v", bs.ToIndices())
+	}
+}
+
+func TestBitSet_MarshalCompressed(t *testing.T) {
+	bs := NewBitSetWithInitialSize(100000)
+	for i := 0; i < bs.Size(); i += 10 {
+		bs.Set(i)
+	}
+
+	data, err := bs.MarshalCompressed()
+	if err != nil {
+		t.Fatalf("BitSet.MarshalCompressed() returned error: %v", err)
+	}
+	res := NewBitSet()
+	if err := res.UnmarshalCompressed(data); err != nil {
+		t.Fatalf("BitSet.UnmarshalCompressed() returned error: %v", err)
+	}
+	if res.Size() != bs.Size() || !res.Equal(bs) {
+		t.Errorf("BitSet.UnmarshalCompressed() (size %d) doesn't match the encoded bitset (size %d)", res.Size(), bs.Size())
+	}
+
+	binaryData, _ := bs.MarshalBinary()
+	if len(data) >= len(binaryData)/10 {
+		t.Errorf("BitSet.MarshalCompressed() is %d bytes, want much less than MarshalBinary's %d", len(data), len(binaryData))
+	}
+	if err := res.UnmarshalCompressed(data[:len(data)/2]); err == nil {
+		t.Errorf("BitSet.UnmarshalCompressed() on truncated data returned nil error")
+	}
+	if err := res.UnmarshalCompressed([]byte{0xff, 0xff, 0xff, 0x7f, 1}); err == nil {
+		t.Errorf("BitSet.UnmarshalCompressed() with oversized header returned nil error")
+	}
+}
+
+func TestBitSet_MarshalSparse(t *testing.T) {
+	bs := NewBitSetWithInitialSize(1000000)
+	bs.SetBits([]int{0, 1, 5000, 654321, 999999})
+
+	data, err := bs.MarshalSparse()
+	if err != nil {
+		t.Fatalf("BitSet.MarshalSparse() returned error: %v", err)
+	}
+	res := NewBitSet()
+	if err := res.UnmarshalSparse(data); err != nil {
+		t.Fatalf("BitSet.UnmarshalSparse() returned error: %v", err)
+	}
+	if res.Size() != bs.Size() || !slices.Equal(res.ToIndices(), bs.ToIndices()) {
+		t.Errorf("BitSet.UnmarshalSparse() == %v (size %d), want %v (size %d)", res.ToIndices(), res.Size(), bs.ToIndices(), bs.Size())
+	}
+
+	binaryData, _ := bs.MarshalBinary()
+	if len(data) > 20 {
+		t.Errorf("BitSet.MarshalSparse() is %d bytes, want <= 20 vs MarshalBinary's %d", len(data), len(binaryData))
+	}
+
+	// size 10 with a gap running past the end
+	if err := res.UnmarshalSparse([]byte{10, 3, 6}); err == nil {
+		t.Errorf("BitSet.UnmarshalSparse() with out-of-range bit returned nil error")
+	}
+	if err := res.UnmarshalSparse(nil); err == nil {
+		t.Errorf("BitSet.UnmarshalSparse(nil) returned nil error")
+	}
+	for _, size := range []uint64{1 << 62, 1 << 33, maxDecodedSize + 1} {
+		if err := res.UnmarshalSparse(binary.AppendUvarint(nil, size)); err == nil {
+			t.Errorf("BitSet.UnmarshalSparse() with size %d returned nil error", size)
+		}
+	}
+	// a size that would wrap to 0 as a 32-bit int, followed by a set bit
+	if err := res.UnmarshalSparse(binary.AppendUvarint(binary.AppendUvarint(nil, 1<<33), 100)); err == nil {
+		t.Errorf("BitSet.UnmarshalSparse() with size 1<<33 and a set bit returned nil error")
+	}
+	if !slices.Equal(res.ToIndices(), bs.ToIndices()) {
+		t.Errorf("BitSet.UnmarshalSparse() with invalid data modified the bitset to %v", res.ToIndices())
+	}
+}
+
+func TestBitSet_RunLengthEncode(t *testing.T) {
+	bs := NewBitSetWithInitialSize(10000)
+	for _, r := range [][2]int{{0, 100}, {500, 3000}, {4000, 4001}, {9000, 10000}} {
+		for i := r[0]; i < r[1]; i++ {
+			bs.Set(i)
+		}
+	}
+
+	data := bs.RunLengthEncode()
+	res, err := NewBitSetFromRunLength(data)
+	if err != nil {
+		t.Fatalf("NewBitSetFromRunLength() returned error: %v", err)
+	}
+	if res.Size() != bs.Size() || !slices.Equal(res.ToIndices(), bs.ToIndices()) {
+		t.Errorf("NewBitSetFromRunLength() (size %d) doesn't match the encoded bitset (size %d)", res.Size(), bs.Size())
+	}
+	binaryData, _ := bs.MarshalBinary()
+	if len(data) >= len(binaryData)/10 {
+		t.Errorf("BitSet.RunLengthEncode() is %d bytes, want much less than MarshalBinary's %d", len(data), len(binaryData))
+	}
+
+	long := NewBitSetWithInitialSize(1<<24).SetRange(3, 1<<24-5)
+	if res, err := NewBitSetFromRunLength(long.RunLengthEncode()); err != nil {
+		t.Errorf("NewBitSetFromRunLength() with a long run returned error: %v", err)
+	} else if !res.EqualStrict(long) {
+		t.Errorf("NewBitSetFromRunLength() with a long run: %d bits set (size %d), want %d bits set (size %d)", res.CountSetBits(), res.Size(), long.CountSetBits(), long.Size())
+	}
+
+	empty := NewBitSetWithInitialSize(0)
+	if res, err := NewBitSetFromRunLength(empty.RunLengthEncode()); err != nil || res.Size() != 0 {
+		t.Errorf("NewBitSetFromRunLength() on empty bitset: Size() == %d, error %v, want 0, nil", res.Size(), err)
+	}
+	for _, data := range [][]byte{
+		nil,
+		{10, 5},
+		{10, 5, 6},
+		{0x80},
+		{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f},
+		binary.AppendUvarint(binary.AppendUvarint(nil, maxDecodedSize+1), maxDecodedSize+1),
+		// a single run that would wrap to 0 as a 32-bit int
+		binary.AppendUvarint(binary.AppendUvarint(nil, 1<<33), 1<<33),
+		binary.AppendUvarint(binary.AppendUvarint(binary.AppendUvarint(nil, 1<<33), 0), 1<<33),
+	} {
+		if _, err := NewBitSetFromRunLength(data); err == nil {
+			t.Errorf("NewBitSetFromRunLength(%v) returned nil error", data)
+		}
+	}
+}
+
+func TestBitSet_ValueScan(t *testing.T) {
+	bs := NewBitSetWithInitialSize(100)
+	bs.SetBits([]int{0, 42, 99})
+
+	value, err := bs.Value()
+	if err != nil {
+		t.Fatalf("BitSet.Value() returned error: %v", err)
+	}
+	res := NewBitSet()
+	if err := res.Scan(value); err != nil {
+		t.Fatalf("BitSet.Scan() returned error: %v", err)
+	}
+	if res.Size() != 100 || !slices.Equal(res.ToIndices(), []int{0, 42, 99}) {
+		t.Errorf("BitSet.Scan() == %v (size %d), want [0 42 99] (size 100)", res.ToIndices(), res.Size())
+	}
+
+	if err := res.Scan(string(value.([]byte))); err != nil || !slices.Equal(res.ToIndices(), []int{0, 42, 99}) {
+		t.Errorf("BitSet.Scan() with string source == %v, %v, want [0 42 99], nil", res.ToIndices(), err)
+	}
+	if err := res.Scan(nil); err != nil || res.Size() != 0 || res.Any() {
+		t.Errorf("BitSet.Scan(nil) didn't reset the bitset: size %d, error %v", res.Size(), err)
+	}
+	if err := res.Scan(42); err == nil {
+		t.Errorf("BitSet.Scan(42) returned nil error")
+	}
+}
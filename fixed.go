@@ -0,0 +1,54 @@
+package bitset
+
+// FixedBitSet is a BitSet whose size is fixed at construction. It never reallocates, and its
+// mutators return an error for out-of-range indices instead of growing.
+type FixedBitSet struct {
+	bs *BitSet
+}
+
+// NewFixedBitSet initializes and returns a FixedBitSet holding the given number of bits.
+func NewFixedBitSet(numBits int) *FixedBitSet {
+	return &FixedBitSet{bs: NewBitSetWithInitialSize(numBits)}
+}
+
+// Size returns the number of bits the bitset holds
+func (fbs *FixedBitSet) Size() int {
+	return fbs.bs.size
+}
+
+// Set sets the Nth bit to 1. Errors if n < 0 or n >= bitset.size
+func (fbs *FixedBitSet) Set(n int) error {
+	if err := fbs.bs.checkValidBit(n); err != nil {
+		return err
+	}
+	fbs.bs.set(n)
+	return nil
+}
+
+// Clear zeroes the Nth bit. Errors if n < 0 or n >= bitset.size
+func (fbs *FixedBitSet) Clear(n int) error {
+	if err := fbs.bs.checkValidBit(n); err != nil {
+		return err
+	}
+	fbs.bs.clear(n)
+	return nil
+}
+
+// Flip flips the Nth bit, i.e. 0 -> 1 or 1 -> 0. Errors if n < 0 or n >= bitset.size
+func (fbs *FixedBitSet) Flip(n int) error {
+	if err := fbs.bs.checkValidBit(n); err != nil {
+		return err
+	}
+	fbs.bs.flip(n)
+	return nil
+}
+
+// Test checks if the Nth bit is set to 1. Returns false if n < 0 or n >= bitset.size.
+func (fbs *FixedBitSet) Test(n int) bool {
+	return fbs.bs.Test(n)
+}
+
+// CountSetBits returns the number of set bits.
+func (fbs *FixedBitSet) CountSetBits() int {
+	return fbs.bs.CountSetBits()
+}
@@ -0,0 +1,48 @@
+package bitset
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFixedBitSet(t *testing.T) {
+	fbs, bs := NewFixedBitSet(100), NewBitSetWithInitialSize(100)
+	numWords := len(fbs.bs.words)
+
+	for _, n := range []int{0, 5, 64, 99} {
+		if err := fbs.Set(n); err != nil {
+			t.Errorf("FixedBitSet.Set(%d) returned error: %v", n, err)
+		}
+		bs.Set(n)
+	}
+	if err := fbs.Clear(5); err != nil {
+		t.Errorf("FixedBitSet.Clear(5) returned error: %v", err)
+	}
+	bs.Clear(5)
+	if err := fbs.Flip(6); err != nil {
+		t.Errorf("FixedBitSet.Flip(6) returned error: %v", err)
+	}
+	bs.Flip(6)
+
+	if !slices.Equal(fbs.bs.ToIndices(), bs.ToIndices()) {
+		t.Errorf("FixedBitSet == %v, want %v", fbs.bs.ToIndices(), bs.ToIndices())
+	}
+	if !fbs.Test(99) || fbs.Test(5) || fbs.CountSetBits() != bs.CountSetBits() {
+		t.Errorf("FixedBitSet.Test() or CountSetBits() disagree with BitSet")
+	}
+
+	for _, n := range []int{-1, 100, 1000} {
+		if err := fbs.Set(n); err == nil {
+			t.Errorf("FixedBitSet.Set(%d) returned nil error", n)
+		}
+		if err := fbs.Clear(n); err == nil {
+			t.Errorf("FixedBitSet.Clear(%d) returned nil error", n)
+		}
+		if err := fbs.Flip(n); err == nil {
+			t.Errorf("FixedBitSet.Flip(%d) returned nil error", n)
+		}
+	}
+	if fbs.Size() != 100 || len(fbs.bs.words) != numWords {
+		t.Errorf("FixedBitSet grew: Size() == %d, len(words) == %d, want 100, %d", fbs.Size(), len(fbs.bs.words), numWords)
+	}
+}
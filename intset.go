@@ -0,0 +1,47 @@
+package bitset
+
+import "fmt"
+
+// Integer is a constraint permitting any integer type, matching constraints.Integer.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// IntSet is a set of small non-negative integers backed by a BitSet.
+type IntSet[T Integer] struct {
+	bs *BitSet
+}
+
+// NewIntSet initializes and returns an empty IntSet.
+func NewIntSet[T Integer]() *IntSet[T] {
+	return &IntSet[T]{bs: NewBitSet()}
+}
+
+// Add adds v to the set. Panics if v is negative.
+func (s *IntSet[T]) Add(v T) {
+	s.bs.Set(intSetIndex(v))
+}
+
+// Remove removes v from the set. Panics if v is negative.
+func (s *IntSet[T]) Remove(v T) {
+	s.bs.Clear(intSetIndex(v))
+}
+
+// Contains returns true if v is in the set. Panics if v is negative.
+func (s *IntSet[T]) Contains(v T) bool {
+	return s.bs.Test(intSetIndex(v))
+}
+
+// Len returns the number of values in the set.
+func (s *IntSet[T]) Len() int {
+	return s.bs.CountSetBits()
+}
+
+// intSetIndex converts v to a bit index, panicking if v is negative.
+func intSetIndex[T Integer](v T) int {
+	if v < 0 {
+		panic(fmt.Sprintf("bitset: negative value %d in IntSet", v))
+	}
+	return int(v)
+}
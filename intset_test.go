@@ -0,0 +1,41 @@
+package bitset
+
+import "testing"
+
+func TestIntSet_Int(t *testing.T) {
+	s := NewIntSet[int]()
+	s.Add(3)
+	s.Add(100)
+	s.Add(3)
+
+	if !s.Contains(3) || !s.Contains(100) || s.Contains(4) {
+		t.Errorf("IntSet.Contains() == %v, %v, %v, want true, true, false", s.Contains(3), s.Contains(100), s.Contains(4))
+	}
+	if s.Len() != 2 {
+		t.Errorf("IntSet.Len() == %d, want 2", s.Len())
+	}
+	s.Remove(3)
+	if s.Contains(3) || s.Len() != 1 {
+		t.Errorf("IntSet.Remove(3): Contains(3) == %v, Len() == %d, want false, 1", s.Contains(3), s.Len())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("IntSet.Add(-1) didn't panic")
+		}
+	}()
+	s.Add(-1)
+}
+
+func TestIntSet_Uint16(t *testing.T) {
+	s := NewIntSet[uint16]()
+	for _, v := range []uint16{0, 1, 65535} {
+		s.Add(v)
+	}
+	if !s.Contains(65535) || !s.Contains(0) || s.Contains(2) {
+		t.Errorf("IntSet[uint16].Contains() == %v, %v, %v, want true, true, false", s.Contains(65535), s.Contains(0), s.Contains(2))
+	}
+	if s.Len() != 3 {
+		t.Errorf("IntSet[uint16].Len() == %d, want 3", s.Len())
+	}
+}
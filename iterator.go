@@ -0,0 +1,41 @@
+package bitset
+
+import (
+	"iter"
+	"math/bits"
+)
+
+// BitSetIterator yields the indices of the set bits of a BitSet in ascending order. It can be
+// abandoned at any point. Modifying the bitset while iterating may or may not be reflected.
+type BitSetIterator struct {
+	bs      *BitSet
+	wordIdx int
+	word    uint64 // the remaining set bits of the current word
+}
+
+// Iterator returns a BitSetIterator over the set bits of the bitset.
+func (bs *BitSet) Iterator() *BitSetIterator {
+	return &BitSetIterator{bs: bs, word: bs.getWord(0)}
+}
+
+// Next returns the index of the next set bit, or ok == false once all set bits have been yielded.
+func (it *BitSetIterator) Next() (index int, ok bool) {
+	for it.word == 0 {
+		it.wordIdx++
+		if it.wordIdx >= len(it.bs.words) {
+			return 0, false
+		}
+		it.word = it.bs.words[it.wordIdx]
+	}
+	index = it.wordIdx*64 + bits.TrailingZeros64(it.word)
+	it.word &= it.word - 1
+	return index, true
+}
+
+// ClearBitsSeq returns an iterator over the indices of the clear bits in [0, bitset.size), in
+// ascending order, for use with range. Bits beyond the size are never yielded.
+func (bs *BitSet) ClearBitsSeq() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		bs.ForEachClearBit(yield)
+	}
+}
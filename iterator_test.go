@@ -0,0 +1,65 @@
+package bitset
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestBitSet_Iterator(t *testing.T) {
+	bs := NewBitSetWithInitialSize(500)
+	for i := 0; i < 100; i++ {
+		bs.Set(rand.Intn(500))
+	}
+	bs.SetBits([]int{0, 63, 64, 499})
+
+	indices := []int{}
+	it := bs.Iterator()
+	for idx, ok := it.Next(); ok; idx, ok = it.Next() {
+		indices = append(indices, idx)
+	}
+	if !slices.Equal(indices, bs.ToIndices()) {
+		t.Errorf("BitSet.Iterator() yielded %v, want %v", indices, bs.ToIndices())
+	}
+	if _, ok := it.Next(); ok {
+		t.Errorf("BitSetIterator.Next() after exhaustion returned ok == true")
+	}
+
+	if _, ok := NewBitSetWithInitialSize(0).Iterator().Next(); ok {
+		t.Errorf("BitSetIterator.Next() on empty bitset returned ok == true")
+	}
+}
+
+func TestBitSet_ClearBitsSeq(t *testing.T) {
+	for _, numBits := range []int{0, 1, 64, 70, 300} {
+		bs := NewBitSetWithInitialSize(numBits)
+		for i := 0; i < numBits/4; i++ {
+			bs.Set(rand.Intn(numBits))
+		}
+
+		var got, want []int
+		for idx := range bs.ClearBitsSeq() {
+			got = append(got, idx)
+		}
+		bs.ForEachClearBit(func(index int) bool {
+			want = append(want, index)
+			return true
+		})
+		if !slices.Equal(got, want) || len(got) != bs.CountClearBits() {
+			t.Errorf("BitSet.ClearBitsSeq() on size %d yielded %v, want %v", numBits, got, want)
+		}
+	}
+
+	bs := NewBitSetWithInitialSize(100)
+	bs.SetBits([]int{1, 3})
+	var got []int
+	for idx := range bs.ClearBitsSeq() {
+		if idx > 5 {
+			break
+		}
+		got = append(got, idx)
+	}
+	if !slices.Equal(got, []int{0, 2, 4, 5}) {
+		t.Errorf("BitSet.ClearBitsSeq() with break yielded %v, want [0 2 4 5]", got)
+	}
+}
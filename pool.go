@@ -0,0 +1,31 @@
+package bitset
+
+import "sync"
+
+var bitSetPool = sync.Pool{
+	New: func() any { return NewBitSetWithInitialSize(0) },
+}
+
+// AcquireBitSet returns a cleared BitSet holding the given number of bits, reusing one previously
+// released with ReleaseBitSet if available. Like NewBitSetWithInitialSize, a negative numBits is
+// treated as 0.
+func AcquireBitSet(numBits int) *BitSet {
+	numBits = max(numBits, 0)
+	bs := bitSetPool.Get().(*BitSet)
+	numWords := max(1, (numBits+63)/64)
+	if cap(bs.words) < numWords {
+		bs.words = make([]uint64, numWords)
+	} else {
+		bs.words = bs.words[:numWords]
+		clear(bs.words)
+	}
+	bs.size, bs.growth = numBits, nil
+	return bs
+}
+
+// ReleaseBitSet clears bs and returns it to the pool used by AcquireBitSet. The bitset must not be
+// used after it's released.
+func ReleaseBitSet(bs *BitSet) {
+	bs.Reset()
+	bitSetPool.Put(bs)
+}
@@ -0,0 +1,36 @@
+package bitset
+
+import "testing"
+
+func TestAcquireBitSet(t *testing.T) {
+	bs := AcquireBitSet(200)
+	if bs.Size() != 200 || bs.Any() {
+		t.Errorf("AcquireBitSet(200): Size() == %d, Any() == %v, want 200, false", bs.Size(), bs.Any())
+	}
+	bs.SetBits([]int{0, 100, 199})
+	ReleaseBitSet(bs)
+
+	for _, numBits := range []int{64, 200, 1000} {
+		bs = AcquireBitSet(numBits)
+		if bs.Size() != numBits || bs.Any() {
+			t.Errorf("AcquireBitSet(%d) after release: Size() == %d, Any() == %v, want %d, false", numBits, bs.Size(), bs.Any(), numBits)
+		}
+		if bs.Cap() < numBits {
+			t.Errorf("AcquireBitSet(%d): Cap() == %d, want >= %d", numBits, bs.Cap(), numBits)
+		}
+		bs.Set(numBits - 1)
+		ReleaseBitSet(bs)
+	}
+}
+
+func TestAcquireBitSet_Negative(t *testing.T) {
+	bs := AcquireBitSet(-5)
+	if bs.Size() != 0 || bs.CountClearBits() != 0 || bs.Any() {
+		t.Errorf("AcquireBitSet(-5): Size() == %d, CountClearBits() == %d, want 0, 0", bs.Size(), bs.CountClearBits())
+	}
+	bs.Set(3)
+	if bs.Size() != 4 || !bs.Test(3) {
+		t.Errorf("AcquireBitSet(-5) then Set(3): Size() == %d, Test(3) == %v, want 4, true", bs.Size(), bs.Test(3))
+	}
+	ReleaseBitSet(bs)
+}